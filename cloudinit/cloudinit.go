@@ -0,0 +1,266 @@
+package cloudinit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// MaxUserDataSize is maximum size of ECS user data before base64 encoding
+	MaxUserDataSize = 32 * 1024
+	// MaxInstallScriptSize is maximum size of CCE node pre- or post-install script
+	MaxInstallScriptSize = 1000
+
+	cloudConfigHeader = "#cloud-config\n"
+	scriptHeader      = "#!/bin/sh\n"
+	mimeBoundary      = "==CRUTCH-HOUSE-BOUNDARY=="
+)
+
+var (
+	ErrEmpty            = errors.New("user data is empty")
+	ErrTooLarge         = errors.New("user data size exceeds the limit")
+	ErrNotScriptable    = errors.New("only commands and scripts can be used in install script")
+	ErrInvalidUser      = errors.New("user name is required")
+	ErrInvalidFile      = errors.New("file path is required")
+	ErrBoundaryConflict = errors.New("part content contains MIME boundary")
+)
+
+// User is cloud-config `users` entry
+type User struct {
+	Name              string   `yaml:"name"`
+	Groups            string   `yaml:"groups,omitempty"`
+	Shell             string   `yaml:"shell,omitempty"`
+	Sudo              string   `yaml:"sudo,omitempty"`
+	LockPassword      *bool    `yaml:"lock_passwd,omitempty"`
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+}
+
+// File is cloud-config `write_files` entry
+type File struct {
+	Path        string `yaml:"path"`
+	Content     string `yaml:"content"`
+	Encoding    string `yaml:"encoding,omitempty"`
+	Owner       string `yaml:"owner,omitempty"`
+	Permissions string `yaml:"permissions,omitempty"`
+	Append      bool   `yaml:"append,omitempty"`
+}
+
+// CloudConfig is a subset of cloud-config document supported by Builder
+type CloudConfig struct {
+	Users             []interface{} `yaml:"users,omitempty"`
+	SSHAuthorizedKeys []string      `yaml:"ssh_authorized_keys,omitempty"`
+	PackageUpdate     bool          `yaml:"package_update,omitempty"`
+	PackageUpgrade    bool          `yaml:"package_upgrade,omitempty"`
+	Packages          []string      `yaml:"packages,omitempty"`
+	WriteFiles        []File        `yaml:"write_files,omitempty"`
+	RunCmd            []string      `yaml:"runcmd,omitempty"`
+}
+
+// Script is shell script part of multipart user data
+type Script struct {
+	Name    string
+	Content string
+}
+
+// Builder composes cloud-init user data from typed parts
+type Builder struct {
+	config  CloudConfig
+	scripts []Script
+	err     error
+}
+
+// NewBuilder creates empty user data builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// KeepDefaultUser keeps distribution default user when custom users are added
+func (b *Builder) KeepDefaultUser() *Builder {
+	b.config.Users = append(b.config.Users, "default")
+	return b
+}
+
+// AddUser adds user with SSH keys
+func (b *Builder) AddUser(user User) *Builder {
+	if user.Name == "" {
+		b.err = ErrInvalidUser
+		return b
+	}
+	b.config.Users = append(b.config.Users, user)
+	return b
+}
+
+// AddSSHKeys adds authorized SSH keys for the default user
+func (b *Builder) AddSSHKeys(keys ...string) *Builder {
+	for _, key := range keys {
+		b.config.SSHAuthorizedKeys = append(b.config.SSHAuthorizedKeys, strings.TrimSpace(key))
+	}
+	return b
+}
+
+// AddPackages adds packages to be installed, package index is updated before installation
+func (b *Builder) AddPackages(packages ...string) *Builder {
+	b.config.PackageUpdate = true
+	b.config.Packages = append(b.config.Packages, packages...)
+	return b
+}
+
+// UpgradePackages enables upgrade of all installed packages
+func (b *Builder) UpgradePackages() *Builder {
+	b.config.PackageUpgrade = true
+	return b
+}
+
+// AddFile adds file to be written
+func (b *Builder) AddFile(file File) *Builder {
+	if file.Path == "" {
+		b.err = ErrInvalidFile
+		return b
+	}
+	b.config.WriteFiles = append(b.config.WriteFiles, file)
+	return b
+}
+
+// AddCommands adds shell commands to be run on the first boot
+func (b *Builder) AddCommands(commands ...string) *Builder {
+	b.config.RunCmd = append(b.config.RunCmd, commands...)
+	return b
+}
+
+// AddScript adds shell script as separate part of multipart user data
+// Shebang is added if script has none
+func (b *Builder) AddScript(name string, content string) *Builder {
+	if !strings.HasPrefix(content, "#!") {
+		content = scriptHeader + content
+	}
+	b.scripts = append(b.scripts, Script{Name: name, Content: content})
+	return b
+}
+
+func (b *Builder) hasConfig() bool {
+	c := b.config
+	return len(c.Users) > 0 || len(c.SSHAuthorizedKeys) > 0 || len(c.Packages) > 0 ||
+		c.PackageUpgrade || len(c.WriteFiles) > 0 || len(c.RunCmd) > 0
+}
+
+// CloudConfig renders cloud-config document ignoring added scripts
+func (b *Builder) CloudConfig() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	data, err := yaml.Marshal(b.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render cloud-config: %s", err)
+	}
+	return append([]byte(cloudConfigHeader), data...), nil
+}
+
+// Build renders user data ready to be used as `ExtendedServerOpts.UserData`
+// Plain cloud-config is returned if no scripts are added, multipart MIME document otherwise
+func (b *Builder) Build() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if !b.hasConfig() && len(b.scripts) == 0 {
+		return nil, ErrEmpty
+	}
+
+	var result []byte
+	var err error
+	if len(b.scripts) == 0 {
+		result, err = b.CloudConfig()
+	} else {
+		result, err = b.multipart()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > MaxUserDataSize {
+		return nil, fmt.Errorf("%w: %d > %d bytes", ErrTooLarge, len(result), MaxUserDataSize)
+	}
+	return result, nil
+}
+
+func (b *Builder) multipart() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("Content-Type: multipart/mixed; boundary=\"" + mimeBoundary + "\"\n")
+	buf.WriteString("MIME-Version: 1.0\n\n")
+
+	w := multipart.NewWriter(buf)
+	if err := w.SetBoundary(mimeBoundary); err != nil {
+		return nil, err
+	}
+	if b.hasConfig() {
+		config, err := b.CloudConfig()
+		if err != nil {
+			return nil, err
+		}
+		if err := writePart(w, "text/cloud-config", "cloud-config.txt", config); err != nil {
+			return nil, err
+		}
+	}
+	for i, script := range b.scripts {
+		name := script.Name
+		if name == "" {
+			name = fmt.Sprintf("script-%d.sh", i)
+		}
+		if err := writePart(w, "text/x-shellscript", name, []byte(script.Content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writePart(w *multipart.Writer, contentType string, fileName string, content []byte) error {
+	if bytes.Contains(content, []byte(mimeBoundary)) {
+		return ErrBoundaryConflict
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=\"us-ascii\"")
+	header.Set("Content-Disposition", "attachment; filename=\""+fileName+"\"")
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(content)
+	return err
+}
+
+// InstallScript renders commands and scripts as single shell script
+// ready to be used as `CreateNodesOpts.PreInstall` or `CreateNodesOpts.PostInstall`
+func (b *Builder) InstallScript() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	c := b.config
+	if len(c.Users) > 0 || len(c.SSHAuthorizedKeys) > 0 || len(c.Packages) > 0 ||
+		c.PackageUpgrade || len(c.WriteFiles) > 0 {
+		return "", ErrNotScriptable
+	}
+	if len(c.RunCmd) == 0 && len(b.scripts) == 0 {
+		return "", ErrEmpty
+	}
+
+	lines := append([]string{}, c.RunCmd...)
+	for _, script := range b.scripts {
+		content := script.Content
+		if strings.HasPrefix(content, "#!") {
+			content = content[strings.Index(content, "\n")+1:]
+		}
+		lines = append(lines, strings.TrimRight(content, "\n"))
+	}
+	result := strings.Join(lines, "\n") + "\n"
+	if len(result) > MaxInstallScriptSize {
+		return "", fmt.Errorf("%w: %d > %d bytes", ErrTooLarge, len(result), MaxInstallScriptSize)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package cloudinit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_CloudConfig(t *testing.T) {
+	data, err := NewBuilder().
+		KeepDefaultUser().
+		AddUser(User{Name: "ci", Sudo: "ALL=(ALL) NOPASSWD:ALL", SSHAuthorizedKeys: []string{"ssh-rsa AAAA ci"}}).
+		AddPackages("nginx").
+		AddFile(File{Path: "/etc/motd", Content: "hello", Permissions: "0644"}).
+		AddCommands("systemctl start nginx").
+		Build()
+	require.NoError(t, err)
+
+	expected := `#cloud-config
+users:
+- default
+- name: ci
+  sudo: ALL=(ALL) NOPASSWD:ALL
+  ssh_authorized_keys:
+  - ssh-rsa AAAA ci
+package_update: true
+packages:
+- nginx
+write_files:
+- path: /etc/motd
+  content: hello
+  permissions: "0644"
+runcmd:
+- systemctl start nginx
+`
+	assert.Equal(t, expected, string(data))
+}
+
+func TestBuilder_Multipart(t *testing.T) {
+	data, err := NewBuilder().
+		AddPackages("curl").
+		AddScript("setup.sh", "echo setup").
+		Build()
+	require.NoError(t, err)
+
+	result := string(data)
+	assert.True(t, strings.HasPrefix(result, "Content-Type: multipart/mixed"))
+	assert.Contains(t, result, "Content-Type: text/cloud-config")
+	assert.Contains(t, result, "Content-Type: text/x-shellscript")
+	assert.Contains(t, result, "filename=\"setup.sh\"")
+	assert.Contains(t, result, "#!/bin/sh\necho setup")
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(result), "--"+mimeBoundary+"--"))
+}
+
+func TestBuilder_Validation(t *testing.T) {
+	_, err := NewBuilder().Build()
+	assert.True(t, errors.Is(err, ErrEmpty))
+
+	_, err = NewBuilder().AddUser(User{}).Build()
+	assert.True(t, errors.Is(err, ErrInvalidUser))
+
+	_, err = NewBuilder().AddFile(File{Content: "data"}).Build()
+	assert.True(t, errors.Is(err, ErrInvalidFile))
+
+	_, err = NewBuilder().AddFile(File{Path: "/tmp/big", Content: strings.Repeat("x", MaxUserDataSize)}).Build()
+	assert.True(t, errors.Is(err, ErrTooLarge))
+
+	_, err = NewBuilder().AddScript("", "echo "+mimeBoundary).Build()
+	assert.True(t, errors.Is(err, ErrBoundaryConflict))
+}
+
+func TestBuilder_InstallScript(t *testing.T) {
+	script, err := NewBuilder().
+		AddCommands("mkdir -p /opt/app").
+		AddScript("", "#!/bin/bash\necho done\n").
+		InstallScript()
+	require.NoError(t, err)
+	assert.Equal(t, "mkdir -p /opt/app\necho done\n", script)
+
+	_, err = NewBuilder().AddPackages("curl").InstallScript()
+	assert.True(t, errors.Is(err, ErrNotScriptable))
+
+	_, err = NewBuilder().AddCommands(strings.Repeat("x", MaxInstallScriptSize)).InstallScript()
+	assert.True(t, errors.Is(err, ErrTooLarge))
+}
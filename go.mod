@@ -9,6 +9,7 @@ require (
 	github.com/opentelekomcloud/gophertelekomcloud v0.5.8
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20220331220935-ae2d96664a29
+	gopkg.in/yaml.v2 v2.3.0
 )
 
 require (
@@ -16,6 +17,5 @@ require (
 	github.com/hashicorp/errwrap v1.0.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/sys v0.1.0 // indirect
-	gopkg.in/yaml.v2 v2.3.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
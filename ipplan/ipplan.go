@@ -0,0 +1,317 @@
+package ipplan
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/apparentlymart/go-cidr/cidr"
+	"github.com/hashicorp/go-multierror"
+)
+
+// SubnetRequest is a request for subnet of given size
+type SubnetRequest struct {
+	Name         string
+	PrefixLength int
+	CIDR         string // fixed block, PrefixLength is ignored if set
+}
+
+// VPCRequest is a request for VPC of given size containing subnets
+type VPCRequest struct {
+	Name         string
+	PrefixLength int
+	CIDR         string // fixed block, PrefixLength is ignored if set
+	Subnets      []SubnetRequest
+}
+
+// ExistingSubnet is already existing subnet
+type ExistingSubnet struct {
+	Name string
+	CIDR string
+}
+
+// ExistingVPC is already existing VPC with its subnets
+type ExistingVPC struct {
+	Name    string
+	CIDR    string
+	Subnets []ExistingSubnet
+}
+
+// SubnetAllocation is planned subnet
+type SubnetAllocation struct {
+	Name     string
+	CIDR     string
+	Gateway  string
+	Existing bool // subnet already exists and is reused
+}
+
+// VPCAllocation is planned VPC with its subnets
+type VPCAllocation struct {
+	Name     string
+	CIDR     string
+	Existing bool // VPC already exists and is reused
+	Subnets  []SubnetAllocation
+}
+
+// Plan is non-overlapping allocation of VPCs inside the supernet
+type Plan struct {
+	Supernet string
+	VPCs     []VPCAllocation
+}
+
+// ConflictError describes requested block overlapping with another block
+type ConflictError struct {
+	Name string
+	CIDR string
+	With string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s (%s) conflicts with %s", e.Name, e.CIDR, e.With)
+}
+
+type block struct {
+	name    string
+	network *net.IPNet
+}
+
+// space is address space with reserved blocks
+type space struct {
+	parent   *net.IPNet
+	reserved []block
+}
+
+func newSpace(parent string) (*space, error) {
+	_, network, err := net.ParseCIDR(parent)
+	if err != nil {
+		return nil, err
+	}
+	return &space{parent: network}, nil
+}
+
+func (s *space) overlap(network *net.IPNet) *block {
+	for i, b := range s.reserved {
+		if b.network.Contains(network.IP) || network.Contains(b.network.IP) {
+			return &s.reserved[i]
+		}
+	}
+	return nil
+}
+
+func (s *space) contains(network *net.IPNet) bool {
+	parentLength, _ := s.parent.Mask.Size()
+	length, _ := network.Mask.Size()
+	return length >= parentLength && s.parent.Contains(network.IP)
+}
+
+// reserve marks block as used, returning conflict if it overlaps with reserved one
+func (s *space) reserve(name string, network *net.IPNet) error {
+	if b := s.overlap(network); b != nil {
+		return &ConflictError{Name: name, CIDR: network.String(), With: fmt.Sprintf("%s (%s)", b.name, b.network)}
+	}
+	s.reserved = append(s.reserved, block{name: name, network: network})
+	return nil
+}
+
+// allocate reserves the first free block of given prefix length
+func (s *space) allocate(name string, prefixLength int) (*net.IPNet, error) {
+	parentLength, bits := s.parent.Mask.Size()
+	if prefixLength < parentLength || prefixLength > bits {
+		return nil, fmt.Errorf("prefix length /%d doesn't fit into %s", prefixLength, s.parent)
+	}
+	candidate, err := cidr.Subnet(s.parent, prefixLength-parentLength, 0)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if s.overlap(candidate) == nil {
+			s.reserved = append(s.reserved, block{name: name, network: candidate})
+			return candidate, nil
+		}
+		next, exceeded := cidr.NextSubnet(candidate, prefixLength)
+		if exceeded || !s.parent.Contains(next.IP) {
+			return nil, fmt.Errorf("no free /%d block left in %s", prefixLength, s.parent)
+		}
+		candidate = next
+	}
+}
+
+// place reserves fixed, existing or newly allocated block for the request
+func (s *space) place(name, fixed, existing string, prefixLength int) (*net.IPNet, bool, error) {
+	requested := fixed
+	if requested == "" {
+		requested = existing
+	}
+	if requested == "" {
+		if prefixLength == 0 {
+			return nil, false, fmt.Errorf("neither CIDR nor prefix length is set for %s", name)
+		}
+		network, err := s.allocate(name, prefixLength)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %s", name, err)
+		}
+		return network, false, nil
+	}
+
+	_, network, err := net.ParseCIDR(requested)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid CIDR of %s: %s", name, err)
+	}
+	if existing != "" && fixed != "" && existing != network.String() {
+		return nil, false, &ConflictError{Name: name, CIDR: fixed, With: "existing " + existing}
+	}
+	if length, _ := network.Mask.Size(); fixed == "" && prefixLength != 0 && length != prefixLength {
+		return nil, false, &ConflictError{Name: name, CIDR: fmt.Sprintf("/%d", prefixLength), With: "existing " + existing}
+	}
+	if !s.contains(network) {
+		return nil, false, fmt.Errorf("%s (%s) is outside of %s", name, network, s.parent)
+	}
+	if err := s.reserve(name, network); err != nil {
+		return nil, false, err
+	}
+	return network, existing != "", nil
+}
+
+// Overlaps checks if two CIDR blocks overlap
+func Overlaps(first, second string) (bool, error) {
+	_, firstNet, err := net.ParseCIDR(first)
+	if err != nil {
+		return false, err
+	}
+	_, secondNet, err := net.ParseCIDR(second)
+	if err != nil {
+		return false, err
+	}
+	return firstNet.Contains(secondNet.IP) || secondNet.Contains(firstNet.IP), nil
+}
+
+// NextFreeBlock returns the first block of given prefix length inside `parent` not overlapping with `used` blocks
+func NextFreeBlock(parent string, used []string, prefixLength int) (string, error) {
+	s, err := newSpace(parent)
+	if err != nil {
+		return "", err
+	}
+	for _, usedBlock := range used {
+		_, network, err := net.ParseCIDR(usedBlock)
+		if err != nil {
+			return "", err
+		}
+		s.reserved = append(s.reserved, block{name: usedBlock, network: network})
+	}
+	network, err := s.allocate("subnet", prefixLength)
+	if err != nil {
+		return "", err
+	}
+	return network.String(), nil
+}
+
+func gateway(network *net.IPNet) (string, error) {
+	ip, err := cidr.Host(network, 1)
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// NewPlan allocates requested VPCs and subnets inside the supernet
+//
+// Requests are processed in the given order, so adding new requests to the end
+// of the list doesn't change allocation of previous ones. Existing VPCs and subnets
+// with the same name as requested ones are reused, other existing VPCs are treated
+// as reserved address space.
+func NewPlan(supernet string, requests []VPCRequest, existing []ExistingVPC) (*Plan, error) {
+	super, err := newSpace(supernet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid supernet: %s", err)
+	}
+
+	existingByName := make(map[string]ExistingVPC, len(existing))
+	requested := make(map[string]bool, len(requests))
+	for _, vpc := range requests {
+		if requested[vpc.Name] {
+			return nil, fmt.Errorf("VPC %s is requested more than once", vpc.Name)
+		}
+		requested[vpc.Name] = true
+	}
+	mErr := &multierror.Error{}
+	for _, vpc := range existing {
+		existingByName[vpc.Name] = vpc
+		if requested[vpc.Name] {
+			continue
+		}
+		_, network, err := net.ParseCIDR(vpc.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR of existing VPC %s: %s", vpc.Name, err)
+		}
+		// VPCs outside of supernet can't conflict with planned ones, existing VPCs may overlap each other
+		if super.contains(network) || network.Contains(super.parent.IP) {
+			super.reserved = append(super.reserved, block{name: "existing VPC " + vpc.Name, network: network})
+		}
+	}
+
+	plan := &Plan{Supernet: super.parent.String()}
+	for _, request := range requests {
+		current, exists := existingByName[request.Name]
+		network, reused, err := super.place(request.Name, request.CIDR, current.CIDR, request.PrefixLength)
+		if err != nil {
+			mErr = multierror.Append(mErr, err)
+			continue
+		}
+		allocation := VPCAllocation{Name: request.Name, CIDR: network.String(), Existing: reused && exists}
+		subnets, err := planSubnets(network, request.Subnets, current.Subnets)
+		if err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("VPC %s: %s", request.Name, err))
+			continue
+		}
+		allocation.Subnets = subnets
+		plan.VPCs = append(plan.VPCs, allocation)
+	}
+	if err := mErr.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func planSubnets(vpcNet *net.IPNet, requests []SubnetRequest, existing []ExistingSubnet) ([]SubnetAllocation, error) {
+	vpcSpace := &space{parent: vpcNet}
+	existingByName := make(map[string]string, len(existing))
+	requested := make(map[string]bool, len(requests))
+	for _, subnet := range requests {
+		if requested[subnet.Name] {
+			return nil, fmt.Errorf("subnet %s is requested more than once", subnet.Name)
+		}
+		requested[subnet.Name] = true
+	}
+	for _, subnet := range existing {
+		existingByName[subnet.Name] = subnet.CIDR
+		if requested[subnet.Name] {
+			continue
+		}
+		_, network, err := net.ParseCIDR(subnet.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR of existing subnet %s: %s", subnet.Name, err)
+		}
+		vpcSpace.reserved = append(vpcSpace.reserved, block{name: "existing subnet " + subnet.Name, network: network})
+	}
+
+	mErr := &multierror.Error{}
+	result := make([]SubnetAllocation, 0, len(requests))
+	for _, request := range requests {
+		network, reused, err := vpcSpace.place(request.Name, request.CIDR, existingByName[request.Name], request.PrefixLength)
+		if err != nil {
+			mErr = multierror.Append(mErr, err)
+			continue
+		}
+		gw, err := gateway(network)
+		if err != nil {
+			mErr = multierror.Append(mErr, err)
+			continue
+		}
+		result = append(result, SubnetAllocation{
+			Name:     request.Name,
+			CIDR:     network.String(),
+			Gateway:  gw,
+			Existing: reused,
+		})
+	}
+	return result, mErr.ErrorOrNil()
+}
@@ -0,0 +1,173 @@
+package ipplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextFreeBlock(t *testing.T) {
+	block, err := NextFreeBlock("192.168.0.0/20", nil, 24)
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.0.0/24", block)
+
+	block, err = NextFreeBlock("192.168.0.0/20", []string{"192.168.0.0/24", "192.168.1.0/25"}, 24)
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.2.0/24", block)
+
+	block, err = NextFreeBlock("10.0.0.0/16", []string{"10.0.0.0/24"}, 22)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.4.0/22", block)
+
+	_, err = NextFreeBlock("192.168.0.0/23", []string{"192.168.0.0/24", "192.168.1.0/24"}, 24)
+	assert.Error(t, err)
+
+	_, err = NextFreeBlock("192.168.0.0/24", nil, 20)
+	assert.Error(t, err)
+}
+
+func TestOverlaps(t *testing.T) {
+	overlaps, err := Overlaps("10.0.0.0/16", "10.0.128.0/24")
+	require.NoError(t, err)
+	assert.True(t, overlaps)
+
+	overlaps, err = Overlaps("10.0.0.0/16", "10.1.0.0/16")
+	require.NoError(t, err)
+	assert.False(t, overlaps)
+
+	_, err = Overlaps("10.0.0.0/16", "invalid")
+	assert.Error(t, err)
+}
+
+var testRequests = []VPCRequest{
+	{
+		Name:         "front",
+		PrefixLength: 20,
+		Subnets: []SubnetRequest{
+			{Name: "web", PrefixLength: 24},
+			{Name: "lb", PrefixLength: 26},
+			{Name: "mgmt", PrefixLength: 24},
+		},
+	},
+	{
+		Name:         "back",
+		PrefixLength: 19,
+		Subnets: []SubnetRequest{
+			{Name: "db", PrefixLength: 22},
+		},
+	},
+}
+
+func TestNewPlan(t *testing.T) {
+	plan, err := NewPlan("10.0.0.0/16", testRequests, nil)
+	require.NoError(t, err)
+	require.Len(t, plan.VPCs, 2)
+
+	front := plan.VPCs[0]
+	assert.Equal(t, "10.0.0.0/20", front.CIDR)
+	assert.False(t, front.Existing)
+	require.Len(t, front.Subnets, 3)
+	assert.Equal(t, "10.0.0.0/24", front.Subnets[0].CIDR)
+	assert.Equal(t, "10.0.0.1", front.Subnets[0].Gateway)
+	assert.Equal(t, "10.0.1.0/26", front.Subnets[1].CIDR)
+	assert.Equal(t, "10.0.2.0/24", front.Subnets[2].CIDR)
+
+	back := plan.VPCs[1]
+	assert.Equal(t, "10.0.32.0/19", back.CIDR)
+	assert.Equal(t, "10.0.32.0/22", back.Subnets[0].CIDR)
+	assert.Equal(t, "10.0.32.1", back.Subnets[0].Gateway)
+}
+
+func TestNewPlanStable(t *testing.T) {
+	first, err := NewPlan("10.0.0.0/16", testRequests, nil)
+	require.NoError(t, err)
+
+	extended := append([]VPCRequest{}, testRequests...)
+	extended = append(extended, VPCRequest{Name: "extra", PrefixLength: 20})
+	second, err := NewPlan("10.0.0.0/16", extended, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.VPCs, second.VPCs[:len(first.VPCs)])
+	assert.Equal(t, "10.0.16.0/20", second.VPCs[2].CIDR)
+}
+
+func TestNewPlanExisting(t *testing.T) {
+	existing := []ExistingVPC{
+		{Name: "other", CIDR: "10.0.0.0/20"},
+		{Name: "outside", CIDR: "192.168.0.0/16"},
+		{
+			Name: "back",
+			CIDR: "10.0.64.0/19",
+			Subnets: []ExistingSubnet{
+				{Name: "db", CIDR: "10.0.64.0/22"},
+				{Name: "legacy", CIDR: "10.0.68.0/24"},
+			},
+		},
+	}
+	requests := []VPCRequest{
+		testRequests[0],
+		{
+			Name:         "back",
+			PrefixLength: 19,
+			Subnets: []SubnetRequest{
+				{Name: "db", PrefixLength: 22},
+				{Name: "cache", PrefixLength: 24},
+			},
+		},
+	}
+	plan, err := NewPlan("10.0.0.0/16", requests, existing)
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.0.16.0/20", plan.VPCs[0].CIDR)
+
+	back := plan.VPCs[1]
+	assert.True(t, back.Existing)
+	assert.Equal(t, "10.0.64.0/19", back.CIDR)
+	assert.True(t, back.Subnets[0].Existing)
+	assert.Equal(t, "10.0.64.0/22", back.Subnets[0].CIDR)
+	assert.False(t, back.Subnets[1].Existing)
+	assert.Equal(t, "10.0.69.0/24", back.Subnets[1].CIDR)
+
+	overlapping := []ExistingVPC{
+		{Name: "small", CIDR: "10.0.0.0/24"},
+		{Name: "large", CIDR: "10.0.0.0/16"},
+	}
+	plan, err = NewPlan("10.0.0.0/8", []VPCRequest{{Name: "new", PrefixLength: 24}}, overlapping)
+	require.NoError(t, err)
+	assert.Equal(t, "10.1.0.0/24", plan.VPCs[0].CIDR)
+}
+
+func TestNewPlanConflicts(t *testing.T) {
+	existing := []ExistingVPC{
+		{Name: "other", CIDR: "10.0.0.0/20"},
+		{Name: "back", CIDR: "10.0.64.0/19"},
+	}
+	requests := []VPCRequest{
+		{Name: "fixed", CIDR: "10.0.8.0/21"},
+		{Name: "back", PrefixLength: 20},
+		{Name: "broken", CIDR: "10.1.0.0/24"},
+	}
+	_, err := NewPlan("10.0.0.0/16", requests, existing)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fixed (10.0.8.0/21) conflicts with existing VPC other (10.0.0.0/20)")
+	assert.Contains(t, err.Error(), "back (/20) conflicts with existing 10.0.64.0/19")
+	assert.Contains(t, err.Error(), "broken (10.1.0.0/24) is outside of 10.0.0.0/16")
+
+	_, err = NewPlan("10.0.0.0/16", []VPCRequest{{Name: "a", PrefixLength: 20}, {Name: "a", PrefixLength: 20}}, nil)
+	assert.Error(t, err)
+
+	_, err = NewPlan("10.0.0.0/16", []VPCRequest{
+		{Name: "a", PrefixLength: 20, Subnets: []SubnetRequest{{Name: "s", PrefixLength: 19}}},
+	}, nil)
+	assert.Error(t, err)
+
+	_, err = NewPlan("10.0.0.0/16", []VPCRequest{
+		{Name: "a", PrefixLength: 20, Subnets: []SubnetRequest{{Name: "s", PrefixLength: 24}, {Name: "s", PrefixLength: 26}}},
+	}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "VPC a: subnet s is requested more than once")
+
+	_, err = NewPlan("10.0.0.0/30", []VPCRequest{{Name: "a", PrefixLength: 31}, {Name: "b", PrefixLength: 31}, {Name: "c", PrefixLength: 31}}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/bandwidths"
+)
+
+// Bandwidth share types
+const (
+	BandwidthDedicated = "PER"
+	BandwidthShared    = "WHOLE"
+)
+
+const bandwidthChargeMode = "bandwidth"
+
+// CreateSharedBandwidth creates shared bandwidth of given size in Mbit/s
+func (c *Client) CreateSharedBandwidth(name string, size int) (*bandwidths.Bandwidth, error) {
+	bandwidth, err := bandwidths.Create(c.NetworkV2, bandwidths.CreateOpts{Name: name, Size: size}).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error creating shared bandwidth: %s", err)
+	}
+	return bandwidth, nil
+}
+
+// GetSharedBandwidth returns shared bandwidth details including its size and EIPs
+func (c *Client) GetSharedBandwidth(bandwidthID string) (*bandwidths.Bandwidth, error) {
+	return bandwidths.Get(c.NetworkV2, bandwidthID).Extract()
+}
+
+// ListSharedBandwidthMembers returns EIPs using shared bandwidth
+func (c *Client) ListSharedBandwidthMembers(bandwidthID string) ([]bandwidths.PublicIpInfo, error) {
+	bandwidth, err := c.GetSharedBandwidth(bandwidthID)
+	if err != nil {
+		return nil, err
+	}
+	return bandwidth.PublicIpInfo, nil
+}
+
+// ResizeSharedBandwidth changes size of shared bandwidth in Mbit/s
+func (c *Client) ResizeSharedBandwidth(bandwidthID string, size int) (*bandwidths.Bandwidth, error) {
+	bandwidth, err := bandwidths.Update(c.NetworkV2, bandwidthID, bandwidths.UpdateOpts{Size: size}).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error resizing shared bandwidth `%s`: %s", bandwidthID, err)
+	}
+	return bandwidth, nil
+}
+
+// AddEIPsToSharedBandwidth moves EIPs from their dedicated bandwidths into shared bandwidth
+func (c *Client) AddEIPsToSharedBandwidth(bandwidthID string, eipIDs ...string) error {
+	opts := bandwidths.InsertOpts{}
+	for _, eipID := range eipIDs {
+		opts.PublicIpInfo = append(opts.PublicIpInfo, bandwidths.PublicIpInfoInsertOpts{PublicIpID: eipID})
+	}
+	if err := bandwidths.Insert(c.NetworkV2, bandwidthID, opts).Err; err != nil {
+		return fmt.Errorf("error adding EIPs to shared bandwidth `%s`: %s", bandwidthID, err)
+	}
+	return nil
+}
+
+// RemoveEIPsFromSharedBandwidth moves EIPs from shared bandwidth into dedicated bandwidths of given size in Mbit/s
+func (c *Client) RemoveEIPsFromSharedBandwidth(bandwidthID string, dedicatedSize int, eipIDs ...string) error {
+	opts := bandwidths.RemoveOpts{
+		ChargeMode: bandwidthChargeMode,
+		Size:       dedicatedSize,
+	}
+	for _, eipID := range eipIDs {
+		opts.PublicIpInfo = append(opts.PublicIpInfo, bandwidths.PublicIpInfoID{PublicIpID: eipID})
+	}
+	if err := bandwidths.Remove(c.NetworkV2, bandwidthID, opts).Err; err != nil {
+		return fmt.Errorf("error removing EIPs from shared bandwidth `%s`: %s", bandwidthID, err)
+	}
+	return nil
+}
+
+// DeleteSharedBandwidth removes shared bandwidth, it should have no EIPs
+func (c *Client) DeleteSharedBandwidth(bandwidthID string) error {
+	return bandwidths.Delete(c.NetworkV2, bandwidthID).Err
+}
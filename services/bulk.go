@@ -0,0 +1,153 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+)
+
+const defaultBulkConcurrency = 5
+
+// BulkCreateOpts controls bulk instance creation
+type BulkCreateOpts struct {
+	Concurrency int  // maximum number of parallel create requests, 5 by default
+	Rollback    bool // delete all created instances if any of them fails
+}
+
+// InstanceResult is result of single instance creation in bulk
+type InstanceResult struct {
+	Name     string
+	Instance *servers.Server // nil if instance was not created or was deleted on rollback
+	Err      error
+}
+
+// CreateInstances creates `count` instances with names formatted from `namePattern`
+// using instance index, e.g. `node-%02d`, and waits until all of them are running
+func (c *Client) CreateInstances(opts *ExtendedServerOpts, count int, namePattern string, bulkOpts *BulkCreateOpts) ([]InstanceResult, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("instance count should be positive, got %d", count)
+	}
+	if err := validateNamePattern(namePattern); err != nil {
+		return nil, err
+	}
+	if opts.FixedIP != "" && count > 1 {
+		return nil, fmt.Errorf("fixed IP can't be used for more than one instance")
+	}
+	if bulkOpts == nil {
+		bulkOpts = &BulkCreateOpts{}
+	}
+	concurrency := bulkOpts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	results := make([]InstanceResult, count)
+	semaphore := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			result := &results[idx]
+
+			createOpts := *opts.CreateOpts
+			createOpts.Name = fmt.Sprintf(namePattern, idx)
+			instanceOpts := *opts
+			instanceOpts.CreateOpts = &createOpts
+			result.Name = createOpts.Name
+
+			semaphore <- struct{}{}
+			result.Instance, result.Err = c.CreateInstance(&instanceOpts)
+			<-semaphore
+			if result.Err != nil {
+				return
+			}
+			result.Err = c.waitForInstanceStatusOrError(result.Instance.ID, InstanceStatusRunning, instanceActionTimeout)
+		}(i)
+	}
+	wg.Wait()
+
+	mErr := &multierror.Error{}
+	for _, result := range results {
+		if result.Err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("instance %s: %s", result.Name, result.Err))
+		}
+	}
+	if mErr.ErrorOrNil() == nil || !bulkOpts.Rollback {
+		return results, mErr.ErrorOrNil()
+	}
+
+	var created []string
+	for _, result := range results {
+		if result.Instance != nil {
+			created = append(created, result.Instance.ID)
+		}
+	}
+	log.Printf("Rolling back creation of instances (%s)", strings.Join(created, ","))
+	rollbackErr := c.DeleteInstances(created)
+	if rollbackErr != nil {
+		mErr = multierror.Append(mErr, fmt.Errorf("rollback failed: %s", rollbackErr))
+	}
+	for i := range results {
+		instance := results[i].Instance
+		if instance == nil {
+			continue
+		}
+		if rollbackErr != nil {
+			_, err := c.GetInstanceStatus(instance.ID)
+			if _, gone := err.(golangsdk.ErrDefault404); !gone {
+				continue
+			}
+		}
+		results[i].Instance = nil
+	}
+	return results, mErr.ErrorOrNil()
+}
+
+// validateNamePattern checks that pattern formats instance index into unique names
+func validateNamePattern(namePattern string) error {
+	first := fmt.Sprintf(namePattern, 0)
+	if strings.Contains(first, "%!") || first == fmt.Sprintf(namePattern, 1) {
+		return fmt.Errorf("name pattern `%s` should contain single integer verb for instance index", namePattern)
+	}
+	return nil
+}
+
+func (c *Client) waitForInstanceDeleted(instanceID string) error {
+	return golangsdk.WaitFor(instanceActionTimeout, func() (bool, error) {
+		_, err := c.GetInstanceStatus(instanceID)
+		if err == nil {
+			return false, nil
+		}
+		switch err.(type) {
+		case golangsdk.ErrDefault404:
+			return true, nil
+		default:
+			return true, err
+		}
+	})
+}
+
+// DeleteInstances deletes all given instances and waits until they are gone
+func (c *Client) DeleteInstances(instanceIDs []string) error {
+	errChan := make(chan error, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		go func(id string) {
+			if err := c.DeleteInstance(id); err != nil {
+				errChan <- err
+				return
+			}
+			errChan <- c.waitForInstanceDeleted(id)
+		}(instanceID)
+	}
+	var err *multierror.Error
+	for range instanceIDs {
+		err = multierror.Append(err, <-errChan)
+	}
+	return err.ErrorOrNil()
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CreateInstances(t *testing.T) {
+	client := computeClient(t)
+
+	subnetID, cleanupNetwork := createTestNetwork(t, client)
+	defer cleanupNetwork()
+
+	opts := testInstanceOpts(t, client, subnetID)
+	defer func() { _ = client.DeleteKeyPair(kpName) }()
+
+	count := 3
+	results, err := client.CreateInstances(opts, count, serverName+"-%d", &BulkCreateOpts{
+		Concurrency: 2,
+		Rollback:    true,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, count)
+
+	var ids []string
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+		require.NotNil(t, result.Instance)
+		ids = append(ids, result.Instance.ID)
+	}
+	assert.NoError(t, client.DeleteInstances(ids))
+}
+
+func TestValidateNamePattern(t *testing.T) {
+	assert.NoError(t, validateNamePattern("node-%02d"))
+	assert.NoError(t, validateNamePattern("node-%d-test"))
+	assert.Error(t, validateNamePattern("node"))
+	assert.Error(t, validateNamePattern("node-%s"))
+	assert.Error(t, validateNamePattern("node-%%"))
+	assert.Error(t, validateNamePattern("node-%d-%d"))
+}
+
+func TestClient_CreateInstancesInvalid(t *testing.T) {
+	client := &Client{}
+	_, err := client.CreateInstances(&ExtendedServerOpts{}, -1, "node-%d", nil)
+	assert.Error(t, err)
+	_, err = client.CreateInstances(&ExtendedServerOpts{}, 2, "node", nil)
+	assert.Error(t, err)
+}
@@ -26,6 +26,9 @@ type Client struct {
 	NetworkV2 *golangsdk.ServiceClient
 	VPC       *golangsdk.ServiceClient
 	CCE       *golangsdk.ServiceClient
+	ImageV2   *golangsdk.ServiceClient
+	NatV2     *golangsdk.ServiceClient
+	FlowLogs  *golangsdk.ServiceClient
 
 	cloud *openstack.Cloud
 }
@@ -104,10 +107,14 @@ func (c *Client) NewServiceClient(service string) (*golangsdk.ServiceClient, err
 		return openstack.NewNetworkV1(c.Provider, eo)
 	case "network":
 		return openstack.NewNetworkV2(c.Provider, eo)
+	case "flowlogs":
+		return openstack.NewNetworkV1(c.Provider, eo)
 	case "object-store":
 		return openstack.NewObjectStorageV1(c.Provider, eo)
 	case "cce":
 		return openstack.NewCCE(c.Provider, eo)
+	case "nat":
+		return openstack.NewNatV2(c.Provider, eo)
 	case "orchestration":
 		return openstack.NewOrchestrationV1(c.Provider, eo)
 	case "sharev2":
@@ -1,12 +1,15 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"sort"
 	"strings"
 
 	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/attachinterfaces"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/bootfromvolume"
-	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/floatingips"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/keypairs"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/schedulerhints"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/secgroups"
@@ -17,14 +20,20 @@ import (
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/imageservice/v2/images"
 	"github.com/opentelekomcloud/gophertelekomcloud/pagination"
+
+	"github.com/opentelekomcloud-infra/crutch-house/ssh"
 )
 
 // Instance statuses
 const (
-	InstanceStatusStopped = "SHUTOFF"
-	InstanceStatusRunning = "ACTIVE"
+	InstanceStatusStopped      = "SHUTOFF"
+	InstanceStatusRunning      = "ACTIVE"
+	InstanceStatusVerifyResize = "VERIFY_RESIZE"
+	InstanceStatusError        = "ERROR"
 )
 
+const instanceActionTimeout = 1200
+
 // InitCompute initializes Compute v2 service
 func (c *Client) InitCompute() error {
 	if c.ComputeV2 != nil {
@@ -75,6 +84,7 @@ func (c *Client) CreateInstance(opts *ExtendedServerOpts) (*servers.Server, erro
 		UserData:         opts.UserData,
 		AvailabilityZone: opts.AvailabilityZone,
 		Networks:         []servers.Network{{UUID: opts.SubnetID, FixedIP: opts.FixedIP}},
+		Metadata:         opts.Metadata,
 		ServiceClient:    c.ComputeV2,
 	}
 
@@ -127,6 +137,108 @@ func (c *Client) DeleteInstance(instanceID string) error {
 	return servers.Delete(c.ComputeV2, instanceID).Err
 }
 
+func (c *Client) waitForInstanceStatusOrError(instanceID string, status string, timeoutSeconds int) error {
+	return golangsdk.WaitFor(timeoutSeconds, func() (bool, error) {
+		cur, err := c.GetInstanceStatus(instanceID)
+		if err != nil {
+			return true, err
+		}
+		if cur.Status == InstanceStatusError {
+			return true, fmt.Errorf("instance `%s` is in ERROR state: %s", instanceID, cur.Fault.Message)
+		}
+		return cur.Status == status, nil
+	})
+}
+
+// ResizeInstance changes flavor of existing ECS instance
+// Resize is confirmed as soon as instance reaches `VERIFY_RESIZE` status, failed resize is reverted
+func (c *Client) ResizeInstance(instanceID string, flavorName string) error {
+	flavorID, err := c.FindFlavor(flavorName)
+	if err != nil {
+		return err
+	}
+	if flavorID == "" {
+		return fmt.Errorf("flavor `%s` is not found", flavorName)
+	}
+	opts := servers.ResizeOpts{FlavorRef: flavorID}
+	if err := servers.Resize(c.ComputeV2, instanceID, opts).Err; err != nil {
+		return fmt.Errorf("error resizing instance `%s`: %s", instanceID, err)
+	}
+	if err := c.waitForInstanceStatusOrError(instanceID, InstanceStatusVerifyResize, instanceActionTimeout); err != nil {
+		if revertErr := c.revertResize(instanceID); revertErr != nil {
+			return fmt.Errorf("error waiting for instance `%s` resize: %s; revert failed: %s", instanceID, err, revertErr)
+		}
+		return fmt.Errorf("error waiting for instance `%s` resize, resize reverted: %s", instanceID, err)
+	}
+	if err := servers.ConfirmResize(c.ComputeV2, instanceID).Err; err != nil {
+		if revertErr := c.revertResize(instanceID); revertErr != nil {
+			return fmt.Errorf("error confirming instance `%s` resize: %s; revert failed: %s", instanceID, err, revertErr)
+		}
+		return fmt.Errorf("error confirming instance `%s` resize, resize reverted: %s", instanceID, err)
+	}
+	return c.waitForInstanceStatusOrError(instanceID, InstanceStatusRunning, instanceActionTimeout)
+}
+
+// revertResize reverts unconfirmed resize and waits until instance is running with the old flavor
+func (c *Client) revertResize(instanceID string) error {
+	if err := servers.RevertResize(c.ComputeV2, instanceID).Err; err != nil {
+		return err
+	}
+	return c.waitForInstanceStatusOrError(instanceID, InstanceStatusRunning, instanceActionTimeout)
+}
+
+// RebuildInstance re-creates ECS instance from the image with given name
+// Image reference in given `opts` is ignored, `opts` can be nil
+func (c *Client) RebuildInstance(instanceID string, imageName string, opts *servers.RebuildOpts) (*servers.Server, error) {
+	imageID, err := c.FindImage(imageName)
+	if err != nil {
+		return nil, err
+	}
+	if imageID == "" {
+		return nil, fmt.Errorf("image `%s` is not found", imageName)
+	}
+	rebuildOpts := servers.RebuildOpts{}
+	if opts != nil {
+		rebuildOpts = *opts
+	}
+	rebuildOpts.ImageID = imageID
+	rebuildOpts.ImageName = ""
+
+	server, err := servers.Rebuild(c.ComputeV2, instanceID, rebuildOpts).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error rebuilding instance `%s`: %s", instanceID, err)
+	}
+	if err := c.waitForInstanceStatusOrError(instanceID, InstanceStatusRunning, instanceActionTimeout); err != nil {
+		return server, fmt.Errorf("error waiting for instance `%s` rebuild: %s", instanceID, err)
+	}
+	return server, nil
+}
+
+// GetInstanceMetadata returns all metadata of the instance
+func (c *Client) GetInstanceMetadata(instanceID string) (map[string]string, error) {
+	return servers.Metadata(c.ComputeV2, instanceID).Extract()
+}
+
+// SetInstanceMetadata replaces all instance metadata with given one
+func (c *Client) SetInstanceMetadata(instanceID string, metadata map[string]string) (map[string]string, error) {
+	return servers.ResetMetadata(c.ComputeV2, instanceID, servers.MetadataOpts(metadata)).Extract()
+}
+
+// UpdateInstanceMetadata creates or updates given instance metadata keys keeping other keys untouched
+func (c *Client) UpdateInstanceMetadata(instanceID string, metadata map[string]string) (map[string]string, error) {
+	return servers.UpdateMetadata(c.ComputeV2, instanceID, servers.MetadataOpts(metadata)).Extract()
+}
+
+// DeleteInstanceMetadata removes given keys from instance metadata
+func (c *Client) DeleteInstanceMetadata(instanceID string, keys ...string) error {
+	for _, key := range keys {
+		if err := servers.DeleteMetadatum(c.ComputeV2, instanceID, key).ExtractErr(); err != nil {
+			return fmt.Errorf("error deleting metadata key `%s` of instance `%s`: %s", key, instanceID, err)
+		}
+	}
+	return nil
+}
+
 // FindInstance returns instance ID by instance Name
 func (c *Client) FindInstance(name string) (string, error) {
 	listOpts := servers.ListOpts{Name: name}
@@ -159,18 +271,120 @@ func (c *Client) WaitForInstanceStatus(instanceID string, status string) error {
 	return servers.WaitForStatus(c.ComputeV2, instanceID, status, 300)
 }
 
+// Instance address types
+const (
+	AddressTypeFixed    = "fixed"
+	AddressTypeFloating = "floating"
+)
+
+// InstanceAddress is a single address assigned to the instance
+type InstanceAddress struct {
+	Network string
+	Address string
+	Version int
+	Type    string // one of AddressType const
+	MAC     string
+}
+
+type rawInstanceAddress struct {
+	Address string `json:"addr"`
+	Version int    `json:"version"`
+	Type    string `json:"OS-EXT-IPS:type"`
+	MAC     string `json:"OS-EXT-IPS-MAC:mac_addr"`
+}
+
+// extractAddresses converts server addresses to the list sorted by network name
+func extractAddresses(addresses map[string]interface{}) ([]InstanceAddress, error) {
+	data, err := json.Marshal(addresses)
+	if err != nil {
+		return nil, err
+	}
+	var pools map[string][]rawInstanceAddress
+	if err := json.Unmarshal(data, &pools); err != nil {
+		return nil, fmt.Errorf("unexpected instance addresses format: %s", err)
+	}
+	networks := make([]string, 0, len(pools))
+	for network := range pools {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+
+	var result []InstanceAddress
+	for _, network := range networks {
+		for _, addr := range pools[network] {
+			result = append(result, InstanceAddress{
+				Network: network,
+				Address: addr.Address,
+				Version: addr.Version,
+				Type:    addr.Type,
+				MAC:     addr.MAC,
+			})
+		}
+	}
+	return result, nil
+}
+
+// GetInstanceAddresses returns all addresses assigned to the instance
+func (c *Client) GetInstanceAddresses(instanceID string) ([]InstanceAddress, error) {
+	instanceDetails, err := c.GetInstanceStatus(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	return extractAddresses(instanceDetails.Addresses)
+}
+
+func (c *Client) findInstanceAddress(instanceID string, addrType string) (string, error) {
+	addresses, err := c.GetInstanceAddresses(instanceID)
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addresses {
+		if addr.Type == addrType && addr.Version == 4 {
+			return addr.Address, nil
+		}
+	}
+	return "", nil
+}
+
+// GetInstancePrivateIP returns fixed IPv4 address of the first instance NIC
+func (c *Client) GetInstancePrivateIP(instanceID string) (string, error) {
+	pages, err := attachinterfaces.List(c.ComputeV2, instanceID).AllPages()
+	if err != nil {
+		return "", err
+	}
+	interfaces, err := attachinterfaces.ExtractInterfaces(pages)
+	if err != nil {
+		return "", err
+	}
+	return primaryFixedIP(interfaces), nil
+}
+
+// primaryFixedIP returns the first fixed IPv4 address in NIC order
+func primaryFixedIP(interfaces []attachinterfaces.Interface) string {
+	for _, iface := range interfaces {
+		for _, ip := range iface.FixedIPs {
+			if parsed := net.ParseIP(ip.IPAddress); parsed != nil && parsed.To4() != nil {
+				return ip.IPAddress
+			}
+		}
+	}
+	return ""
+}
+
+// GetInstancePublicIP returns floating IPv4 address of the instance
+func (c *Client) GetInstancePublicIP(instanceID string) (string, error) {
+	return c.findInstanceAddress(instanceID, AddressTypeFloating)
+}
+
 // InstanceBindToIP checks if instance has IP bind
 func (c *Client) InstanceBindToIP(instanceID string, ip string) (bool, error) {
-	instanceDetails, err := c.GetInstanceStatus(instanceID)
+	addresses, err := c.GetInstanceAddresses(instanceID)
 	if err != nil {
 		return false, err
 	}
-	for _, addrPool := range instanceDetails.Addresses {
-		for _, addrDetails := range addrPool.([]interface{}) {
-			details := addrDetails.(map[string]interface{})
-			if details["addr"] == ip {
-				return true, nil
-			}
+	for _, addr := range addresses {
+		if addr.Address == ip {
+			return true, nil
 		}
 	}
 	return false, nil
@@ -221,6 +435,35 @@ func (c *Client) FindKeyPair(name string) (string, error) {
 	return publicKey, nil
 }
 
+// EnsureKeyPair creates key pair with public key from given source if it doesn't exist yet
+// Existing key pair is reused only if its fingerprint matches the source key
+func (c *Client) EnsureKeyPair(name string, source ssh.PublicKeySource) (*keypairs.KeyPair, error) {
+	publicKey, err := source.AuthorizedKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load public key: %s", err)
+	}
+	fingerprint, err := ssh.PublicKeyFingerprint(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := c.FindKeyPair(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == "" {
+		return c.CreateKeyPair(name, string(publicKey))
+	}
+	existingFingerprint, err := ssh.PublicKeyFingerprint([]byte(existing))
+	if err != nil {
+		return nil, err
+	}
+	if existingFingerprint != fingerprint {
+		return nil, fmt.Errorf("key pair `%s` already exists with different key: %s != %s",
+			name, existingFingerprint, fingerprint)
+	}
+	return keypairs.Get(c.ComputeV2, name).Extract()
+}
+
 // DeleteKeyPair removes existing key pair
 func (c *Client) DeleteKeyPair(name string) error {
 	return keypairs.Delete(c.ComputeV2, name).Err
@@ -378,45 +621,53 @@ func (c *Client) WaitForGroupDeleted(securityGroupID string) error {
 	})
 }
 
-// BindFloatingIP binds floating IP to instance
+// BindFloatingIP binds floating IP to the first NIC of the instance
 func (c *Client) BindFloatingIP(floatingIP, instanceID string) error {
-	opts := floatingips.AssociateOpts{FloatingIP: floatingIP}
-	return floatingips.AssociateInstance(c.ComputeV2, instanceID, opts).Err
+	if err := c.InitNetworkV2(); err != nil {
+		return err
+	}
+	return c.AssociateFloatingIP(floatingIP, instanceID)
 }
 
 // UnbindFloatingIP unbinds floating IP to instance
 func (c *Client) UnbindFloatingIP(floatingIP, instanceID string) error {
-	opts := floatingips.DisassociateOpts{FloatingIP: floatingIP}
-	return floatingips.DisassociateInstance(c.ComputeV2, instanceID, opts).Err
+	if err := c.InitNetworkV2(); err != nil {
+		return err
+	}
+	ip, err := c.getExistingFloatingIP(floatingIP)
+	if err != nil {
+		return err
+	}
+	instancePorts, err := c.instancePorts(instanceID)
+	if err != nil {
+		return err
+	}
+	for _, port := range instancePorts {
+		if port.ID == ip.PortID {
+			return c.DisassociateFloatingIP(floatingIP)
+		}
+	}
+	return fmt.Errorf("floating IP `%s` is not bound to instance `%s`", floatingIP, instanceID)
 }
 
 // FindFloatingIP finds given floating IP and returns ID
-func (c *Client) FindFloatingIP(floatingIP string) (addressID string, err error) {
-	pager := floatingips.List(c.ComputeV2)
-	addressID = ""
-	err = pager.EachPage(func(page pagination.Page) (b bool, err error) {
-		addressList, err := floatingips.ExtractFloatingIPs(page)
-		if err != nil {
-			return false, err
-		}
-		for _, ad := range addressList {
-			if ad.IP == floatingIP {
-				addressID = ad.ID
-				return false, nil
-			}
-		}
-		return true, nil
-	})
-	return
+func (c *Client) FindFloatingIP(floatingIP string) (string, error) {
+	if err := c.InitNetworkV2(); err != nil {
+		return "", err
+	}
+	ip, err := c.FindFloatingIPDetails(floatingIP)
+	if err != nil || ip == nil {
+		return "", err
+	}
+	return ip.ID, nil
 }
 
 // DeleteFloatingIP releases floating IP
 func (c *Client) DeleteFloatingIP(floatingIP string) error {
-	address, err := c.FindFloatingIP(floatingIP)
-	if err != nil {
+	if err := c.InitNetworkV2(); err != nil {
 		return err
 	}
-	return floatingips.Delete(c.ComputeV2, address).Err
+	return c.ReleaseFloatingIP(floatingIP)
 }
 
 func (c *Client) FindServerGroup(groupName string) (result string, err error) {
@@ -443,6 +694,46 @@ func (c *Client) AddTags(instanceID string, serverTags []string) error {
 	return tags.Create(c.ComputeV2, instanceID, opts).Err
 }
 
+// ListTags returns all tags of the instance
+func (c *Client) ListTags(instanceID string) ([]string, error) {
+	serverTags, err := tags.Get(c.ComputeV2, instanceID).Extract()
+	if err != nil {
+		return nil, err
+	}
+	if serverTags == nil {
+		return nil, nil
+	}
+	return serverTags.Tags, nil
+}
+
+// ReplaceTags replaces all instance tags with given ones
+func (c *Client) ReplaceTags(instanceID string, serverTags []string) error {
+	if len(serverTags) == 0 {
+		return c.DeleteTags(instanceID)
+	}
+	return c.AddTags(instanceID, serverTags)
+}
+
+// DeleteTag removes single tag from the instance
+func (c *Client) DeleteTag(instanceID string, tag string) error {
+	current, err := c.ListTags(instanceID)
+	if err != nil {
+		return err
+	}
+	left := make([]string, 0, len(current))
+	for _, t := range current {
+		if t != tag {
+			left = append(left, t)
+		}
+	}
+	return c.ReplaceTags(instanceID, left)
+}
+
+// DeleteTags removes all tags from the instance
+func (c *Client) DeleteTags(instanceID string) error {
+	return tags.Delete(c.ComputeV2, instanceID).Err
+}
+
 func (c *Client) CreateServerGroup(opts *servergroups.CreateOpts) (*servergroups.ServerGroup, error) {
 	return servergroups.Create(c.ComputeV2, opts).Extract()
 }
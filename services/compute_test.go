@@ -2,9 +2,11 @@ package services
 
 import (
 	"log"
+	"path/filepath"
 	"testing"
 
 	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/attachinterfaces"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/servergroups"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +19,7 @@ import (
 const (
 	defaultAZ     = "eu-de-03"
 	defaultFlavor = "s2.large.2"
+	resizeFlavor  = "s2.xlarge.2"
 	defaultImage  = "Standard_Debian_10_latest"
 )
 
@@ -135,6 +138,32 @@ func TestClient_CreateKeyPair(t *testing.T) {
 	assert.Empty(t, found)
 }
 
+func TestClient_EnsureKeyPair(t *testing.T) {
+	client := computeClient(t)
+
+	_ = client.DeleteKeyPair(kpName) // cleanup
+
+	pair := generatePair(t)
+	kp, err := client.EnsureKeyPair(kpName, pair)
+	require.NoError(t, err)
+	defer func() { _ = client.DeleteKeyPair(kpName) }()
+	assert.Equal(t, kpName, kp.Name)
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "id_rsa")
+	require.NoError(t, pair.WriteToFile(privatePath, privatePath+".pub"))
+
+	reused, err := client.EnsureKeyPair(kpName, ssh.FileSource(privatePath))
+	require.NoError(t, err)
+	assert.Equal(t, kp.Fingerprint, reused.Fingerprint)
+
+	_, err = client.EnsureKeyPair(kpName, ssh.FileSource(privatePath+".pub"))
+	assert.NoError(t, err)
+
+	_, err = client.EnsureKeyPair(kpName, generatePair(t))
+	assert.Error(t, err, "fingerprint mismatch expected")
+}
+
 func TestClient_CreateFloatingIP(t *testing.T) {
 	client := computeClient(t)
 	require.NoError(t, client.InitVPC())
@@ -179,6 +208,62 @@ func deleteServerGroup(cl *Client, id string) {
 	_ = cl.DeleteServerGroup(id)
 }
 
+// createTestNetwork creates VPC and subnet returning subnet ID and cleanup function
+func createTestNetwork(t *testing.T, client *Client) (string, func()) {
+	cleanupResources(t)
+	initNetwork(t, client)
+
+	vpc, err := client.CreateVPC(vpcName)
+	require.NoError(t, err)
+	subnet, err := client.CreateSubnet(vpc.ID, subnetName)
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForSubnetStatus(subnet.ID, "ACTIVE"))
+
+	return subnet.ID, func() {
+		deleteSubnet(t, vpc.ID, subnet.ID)
+		deleteVPC(t, vpc.ID)
+	}
+}
+
+func testInstanceOpts(t *testing.T, client *Client, subnetID string) *ExtendedServerOpts {
+	kp, err := client.CreateKeyPair(kpName, "")
+	require.NoError(t, err)
+
+	imgRef, err := client.FindImage(defaultImage)
+	require.NoError(t, err)
+
+	return &ExtendedServerOpts{
+		CreateOpts: &servers.CreateOpts{
+			Name:             serverName,
+			FlavorName:       defaultFlavor,
+			AvailabilityZone: defaultAZ,
+			Metadata:         map[string]string{"owner": "crutch"},
+		},
+		SubnetID:    subnetID,
+		KeyPairName: kp.Name,
+		DiskOpts:    &DiskOpts{SourceID: imgRef, Size: 10, Type: "SATA"},
+	}
+}
+
+// createTestInstance creates running instance in new VPC returning cleanup function
+func createTestInstance(t *testing.T, client *Client) (*servers.Server, func()) {
+	subnetID, cleanupNetwork := createTestNetwork(t, client)
+	opts := testInstanceOpts(t, client, subnetID)
+
+	instance, err := client.CreateInstance(opts)
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForInstanceStatus(instance.ID, InstanceStatusRunning))
+	t.Logf("Instance created: %s", instance.ID)
+
+	return instance, func() {
+		assert.NoError(t, client.DeleteInstance(instance.ID))
+		err := client.WaitForInstanceStatus(instance.ID, "")
+		assert.IsType(t, golangsdk.ErrDefault404{}, err)
+		_ = client.DeleteKeyPair(kpName)
+		cleanupNetwork()
+	}
+}
+
 // Test whole instance + floating IP workflow
 func TestClient_CreateInstance(t *testing.T) {
 	cleanupResources(t)
@@ -269,6 +354,22 @@ func TestClient_CreateInstance(t *testing.T) {
 	assert.NoError(t, client.WaitForInstanceStatus(instance.ID, InstanceStatusRunning))
 }
 
+func TestClient_ResizeInstance(t *testing.T) {
+	client := computeClient(t)
+
+	instance, cleanup := createTestInstance(t, client)
+	defer cleanup()
+
+	require.NoError(t, client.ResizeInstance(instance.ID, resizeFlavor))
+	details, err := client.GetInstanceStatus(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, InstanceStatusRunning, details.Status)
+
+	rebuilt, err := client.RebuildInstance(instance.ID, defaultImage, nil)
+	require.NoError(t, err)
+	assert.Equal(t, instance.ID, rebuilt.ID)
+}
+
 func TestClient_FindFlavor(t *testing.T) {
 	client := computeClient(t)
 	flvID, err := client.FindFlavor(defaultFlavor)
@@ -282,3 +383,108 @@ func TestClient_FindImage(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, imgID)
 }
+
+func TestExtractAddresses(t *testing.T) {
+	addresses := map[string]interface{}{
+		"net-b": []interface{}{
+			map[string]interface{}{
+				"addr":                    "192.168.1.10",
+				"version":                 4,
+				"OS-EXT-IPS:type":         AddressTypeFixed,
+				"OS-EXT-IPS-MAC:mac_addr": "fa:16:3e:00:00:02",
+			},
+		},
+		"net-a": []interface{}{
+			map[string]interface{}{
+				"addr":                    "192.168.0.10",
+				"version":                 4,
+				"OS-EXT-IPS:type":         AddressTypeFixed,
+				"OS-EXT-IPS-MAC:mac_addr": "fa:16:3e:00:00:01",
+			},
+			map[string]interface{}{
+				"addr":                    "80.158.0.1",
+				"version":                 4,
+				"OS-EXT-IPS:type":         AddressTypeFloating,
+				"OS-EXT-IPS-MAC:mac_addr": "fa:16:3e:00:00:01",
+			},
+		},
+	}
+	result, err := extractAddresses(addresses)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, InstanceAddress{
+		Network: "net-a",
+		Address: "192.168.0.10",
+		Version: 4,
+		Type:    AddressTypeFixed,
+		MAC:     "fa:16:3e:00:00:01",
+	}, result[0])
+	assert.Equal(t, AddressTypeFloating, result[1].Type)
+	assert.Equal(t, "net-b", result[2].Network)
+
+	_, err = extractAddresses(map[string]interface{}{"net-a": "invalid"})
+	assert.Error(t, err)
+}
+
+func TestPrimaryFixedIP(t *testing.T) {
+	interfaces := []attachinterfaces.Interface{
+		{FixedIPs: []attachinterfaces.FixedIP{{IPAddress: "fd00::10"}}},
+		{FixedIPs: []attachinterfaces.FixedIP{{IPAddress: "192.168.1.10"}}},
+		{FixedIPs: []attachinterfaces.FixedIP{{IPAddress: "192.168.0.10"}}},
+	}
+	assert.Equal(t, "192.168.1.10", primaryFixedIP(interfaces))
+	assert.Empty(t, primaryFixedIP(nil))
+}
+
+func TestClient_InstanceAddresses(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitNetworkV2())
+
+	instance, cleanup := createTestInstance(t, client)
+	defer cleanup()
+
+	vpcID, err := client.FindVPC(vpcName)
+	require.NoError(t, err)
+	subnetID, err := client.FindSubnet(vpcID, subnetName)
+	require.NoError(t, err)
+	port, err := client.FindInstancePort(instance.ID, subnetID)
+	require.NoError(t, err)
+	require.NotNil(t, port)
+
+	privateIP, err := client.GetInstancePrivateIP(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, port.FixedIPs[0].IPAddress, privateIP)
+
+	eip, err := client.CreateEIP(eipOptions)
+	require.NoError(t, err)
+	defer func() { _ = client.ReleaseEIP(eip.ID) }()
+	require.NoError(t, client.WaitForEIPActive(eip.ID))
+	require.NoError(t, client.BindEIP(eip.ID, port.ID))
+	require.NoError(t, waitForInstanceIPBind(client, instance.ID, eip.PublicAddress, true))
+
+	publicIP, err := client.GetInstancePublicIP(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, eip.PublicAddress, publicIP)
+	assert.NoError(t, client.UnbindEIP(eip.ID))
+}
+
+func TestClient_InstanceMetadata(t *testing.T) {
+	client := computeClient(t)
+
+	instance, cleanup := createTestInstance(t, client)
+	defer cleanup()
+
+	metadata, err := client.GetInstanceMetadata(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "crutch", metadata["owner"])
+
+	metadata, err = client.UpdateInstanceMetadata(instance.ID, map[string]string{"ttl": "1h"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "crutch", "ttl": "1h"}, metadata)
+
+	require.NoError(t, client.DeleteInstanceMetadata(instance.ID, "ttl"))
+
+	metadata, err = client.SetInstanceMetadata(instance.ID, map[string]string{"purpose": "test"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"purpose": "test"}, metadata)
+}
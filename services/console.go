@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+)
+
+// CloudInitFinishedPattern matches final cloud-init message in console log
+var CloudInitFinishedPattern = regexp.MustCompile(`Cloud-init v\. .* finished`)
+
+// GetConsoleOutput returns last `lines` lines of instance console log
+// Whole log is returned if `lines` is 0
+func (c *Client) GetConsoleOutput(instanceID string, lines int) (string, error) {
+	opts := servers.ShowConsoleOutputOpts{Length: lines}
+	return servers.ShowConsoleOutput(c.ComputeV2, instanceID, opts).Extract()
+}
+
+// FollowConsoleOutput polls instance console log passing every new line to `handler`
+// until line matching `pattern` appears or timeout is reached
+// `handler` can be nil
+func (c *Client) FollowConsoleOutput(instanceID string, pattern *regexp.Regexp, timeoutSeconds int, handler func(line string)) error {
+	var seen []string
+	err := golangsdk.WaitFor(timeoutSeconds, func() (bool, error) {
+		output, err := c.GetConsoleOutput(instanceID, 0)
+		if err != nil {
+			return true, err
+		}
+		current := completeLines(output)
+		found := false
+		for _, line := range newConsoleLines(seen, current) {
+			if handler != nil {
+				handler(line)
+			}
+			if pattern.MatchString(line) {
+				found = true
+			}
+		}
+		seen = current
+		return found, nil
+	})
+	if err != nil {
+		return fmt.Errorf("error waiting for `%s` in instance `%s` console: %s", pattern, instanceID, err)
+	}
+	return nil
+}
+
+// WaitForCloudInit waits until cloud-init reports it's finished in instance console
+func (c *Client) WaitForCloudInit(instanceID string, timeoutSeconds int) error {
+	return c.FollowConsoleOutput(instanceID, CloudInitFinishedPattern, timeoutSeconds, nil)
+}
+
+// completeLines splits console output to lines dropping unfinished last line
+func completeLines(output string) []string {
+	end := strings.LastIndex(output, "\n")
+	if end < 0 {
+		return nil
+	}
+	lines := strings.Split(output[:end], "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// newConsoleLines returns lines of `current` output not present in `previous` one
+// Console log can be truncated from the beginning, so the last seen line is searched
+// in current output if it's not simple continuation of the previous one
+func newConsoleLines(previous, current []string) []string {
+	if len(previous) == 0 {
+		return current
+	}
+	if len(current) >= len(previous) && current[len(previous)-1] == previous[len(previous)-1] {
+		return current[len(previous):]
+	}
+	last := previous[len(previous)-1]
+	for i := len(current) - 1; i >= 0; i-- {
+		if current[i] == last {
+			return current[i+1:]
+		}
+	}
+	return current
+}
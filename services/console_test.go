@@ -0,0 +1,24 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteLines(t *testing.T) {
+	assert.Nil(t, completeLines("booting"))
+	assert.Equal(t, []string{"one", "two"}, completeLines("one\r\ntwo\r\nthr"))
+}
+
+func TestNewConsoleLines(t *testing.T) {
+	previous := []string{"one", "two"}
+
+	assert.Equal(t, previous, newConsoleLines(nil, previous))
+	assert.Empty(t, newConsoleLines(previous, previous))
+	assert.Equal(t, []string{"three"}, newConsoleLines(previous, []string{"one", "two", "three"}))
+	// log truncated from the beginning
+	assert.Equal(t, []string{"three", "four"}, newConsoleLines(previous, []string{"two", "three", "four"}))
+	// nothing in common
+	assert.Equal(t, []string{"five"}, newConsoleLines(previous, []string{"five"}))
+}
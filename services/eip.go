@@ -0,0 +1,167 @@
+package services
+
+import (
+	"fmt"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/bandwidths"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/eips"
+)
+
+// EIP statuses
+const (
+	EIPStatusActive = "ACTIVE" // EIP is bound to a port
+	EIPStatusDown   = "DOWN"   // EIP is not bound
+)
+
+const (
+	defaultBandwidthName = "default-bandwidth"
+	eipTimeout           = 60
+)
+
+type ElasticIPOpts struct {
+	IPType            string
+	BandwidthSize     int
+	BandwidthType     string
+	BandwidthName     string // `default-bandwidth` if empty
+	SharedBandwidthID string // EIP is added to existing shared bandwidth if set, other bandwidth options are ignored
+}
+
+func (c *Client) GetEIPStatus(eipID string) (string, error) {
+	eip, err := eips.Get(c.VPC, eipID).Extract()
+	if err != nil {
+		return "", err
+	}
+	return eip.Status, err
+}
+
+func (c *Client) CreateEIP(opts *ElasticIPOpts) (*eips.PublicIp, error) {
+	if opts.IPType == "" {
+		opts.IPType = "5_bgp"
+	}
+	if opts.BandwidthSize == 0 {
+		opts.BandwidthSize = 100
+	}
+	if opts.BandwidthType == "" {
+		opts.BandwidthType = BandwidthDedicated
+	}
+	name := opts.BandwidthName
+	if name == "" {
+		name = defaultBandwidthName
+	}
+
+	bandwidthOpts := eips.BandwidthOpts{
+		Name:      name,
+		Size:      opts.BandwidthSize,
+		ShareType: opts.BandwidthType,
+	}
+	if opts.SharedBandwidthID != "" {
+		bandwidthOpts = eips.BandwidthOpts{
+			Id:        opts.SharedBandwidthID,
+			ShareType: BandwidthShared,
+		}
+	}
+
+	applyOpts := &eips.ApplyOpts{
+		IP: eips.PublicIpOpts{
+			Type: opts.IPType,
+		},
+		Bandwidth: bandwidthOpts,
+	}
+	eip, err := eips.Apply(c.VPC, applyOpts).Extract()
+	if err != nil {
+		return nil, err
+	}
+	return eip, nil
+}
+
+func (c *Client) WaitForEIPActive(eipID string) error {
+	return golangsdk.WaitFor(30, func() (bool, error) {
+		status, err := c.GetEIPStatus(eipID)
+		if err != nil {
+			return true, err
+		}
+		if status == EIPStatusActive || status == EIPStatusDown {
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+// waitForEIPStatus waits until EIP is in given status, empty status means waiting until EIP is released
+func (c *Client) waitForEIPStatus(eipID, status string) error {
+	return golangsdk.WaitFor(eipTimeout, func() (bool, error) {
+		current, err := c.GetEIPStatus(eipID)
+		if err != nil {
+			if _, ok := err.(golangsdk.ErrDefault404); ok && status == "" {
+				return true, nil
+			}
+			return true, err
+		}
+		if current == "ERROR" {
+			return true, fmt.Errorf("EIP `%s` is in ERROR state", eipID)
+		}
+		return current == status, nil
+	})
+}
+
+// ListEIPs returns all EIPs matching the filter
+func (c *Client) ListEIPs(filter eips.ListOpts) ([]eips.PublicIp, error) {
+	return eips.List(c.VPC, filter)
+}
+
+// FindEIP returns EIP details by its public address, nil is returned if there is no such EIP
+func (c *Client) FindEIP(address string) (*eips.PublicIp, error) {
+	eipList, err := c.ListEIPs(eips.ListOpts{PublicIPAddress: address})
+	if err != nil {
+		return nil, err
+	}
+	if len(eipList) == 0 {
+		return nil, nil
+	}
+	return &eipList[0], nil
+}
+
+// eipPortOpts sets EIP port, EIP is unbound if PortID is nil
+type eipPortOpts struct {
+	PortID *string `json:"port_id"`
+}
+
+func (opts eipPortOpts) ToPublicIpUpdateMap() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"publicip": map[string]interface{}{"port_id": opts.PortID},
+	}, nil
+}
+
+// BindEIP binds EIP to the port and waits until EIP is active
+func (c *Client) BindEIP(eipID, portID string) error {
+	if err := eips.Update(c.VPC, eipID, eipPortOpts{PortID: &portID}).Err; err != nil {
+		return fmt.Errorf("error binding EIP `%s` to port `%s`: %s", eipID, portID, err)
+	}
+	return c.waitForEIPStatus(eipID, EIPStatusActive)
+}
+
+// UnbindEIP unbinds EIP from its port and waits until EIP is down
+func (c *Client) UnbindEIP(eipID string) error {
+	if err := eips.Update(c.VPC, eipID, eipPortOpts{}).Err; err != nil {
+		return fmt.Errorf("error unbinding EIP `%s`: %s", eipID, err)
+	}
+	return c.waitForEIPStatus(eipID, EIPStatusDown)
+}
+
+// ReleaseEIP releases EIP and waits until it is gone
+func (c *Client) ReleaseEIP(eipID string) error {
+	if err := eips.Delete(c.VPC, eipID).ExtractErr(); err != nil {
+		return fmt.Errorf("error releasing EIP `%s`: %s", eipID, err)
+	}
+	return c.waitForEIPStatus(eipID, "")
+}
+
+// UpdateBandwidth changes size of EIP bandwidth in Mbit/s
+func (c *Client) UpdateBandwidth(bandwidthID string, size int) (*bandwidths.BandWidth, error) {
+	bandwidth, err := bandwidths.Update(c.VPC, bandwidthID, bandwidths.UpdateOpts{Size: size}).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error updating bandwidth `%s`: %s", bandwidthID, err)
+	}
+	return &bandwidth, nil
+}
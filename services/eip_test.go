@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/eips"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_EIPLifecycle(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitNetworkV2())
+
+	subnetID, cleanupNetwork := createTestNetwork(t, client)
+	defer cleanupNetwork()
+
+	port, err := client.CreatePort(&ExtendedPortOpts{CreateOpts: &ports.CreateOpts{
+		Name:      "test-eip-port",
+		NetworkID: subnetID,
+	}})
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, client.DeletePort(port.ID)) }()
+
+	eip, err := client.CreateEIP(&ElasticIPOpts{BandwidthSize: 2, BandwidthName: "crutch-eip-bandwidth"})
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForEIPActive(eip.ID))
+
+	found, err := client.FindEIP(eip.PublicAddress)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, eip.ID, found.ID)
+
+	bandwidth, err := client.UpdateBandwidth(found.BandwidthID, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, bandwidth.Size)
+	assert.Equal(t, "crutch-eip-bandwidth", bandwidth.Name)
+
+	require.NoError(t, client.BindEIP(eip.ID, port.ID))
+	bound, err := client.ListEIPs(eips.ListOpts{PortID: port.ID})
+	require.NoError(t, err)
+	require.Len(t, bound, 1)
+	assert.Equal(t, eip.ID, bound[0].ID)
+
+	require.NoError(t, client.UnbindEIP(eip.ID))
+	require.NoError(t, client.ReleaseEIP(eip.ID))
+
+	found, err = client.FindEIP(eip.PublicAddress)
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestClient_SharedBandwidth(t *testing.T) {
+	client := authClient(t)
+	initNetwork(t, client)
+	require.NoError(t, client.InitNetworkV2())
+
+	bandwidth, err := client.CreateSharedBandwidth("crutch-shared-bandwidth", 10)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, client.DeleteSharedBandwidth(bandwidth.ID)) }()
+	assert.Equal(t, 10, bandwidth.Size)
+
+	inside, err := client.CreateEIP(&ElasticIPOpts{SharedBandwidthID: bandwidth.ID})
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForEIPActive(inside.ID))
+	defer func() { assert.NoError(t, client.ReleaseEIP(inside.ID)) }()
+
+	dedicated, err := client.CreateEIP(&ElasticIPOpts{BandwidthSize: 2})
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForEIPActive(dedicated.ID))
+	defer func() { assert.NoError(t, client.ReleaseEIP(dedicated.ID)) }()
+
+	require.NoError(t, client.AddEIPsToSharedBandwidth(bandwidth.ID, dedicated.ID))
+	members, err := client.ListSharedBandwidthMembers(bandwidth.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	resized, err := client.ResizeSharedBandwidth(bandwidth.ID, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 20, resized.Size)
+
+	require.NoError(t, client.RemoveEIPsFromSharedBandwidth(bandwidth.ID, 2, dedicated.ID, inside.ID))
+	members, err = client.ListSharedBandwidthMembers(bandwidth.ID)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
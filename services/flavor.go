@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/flavors"
+)
+
+// Flavor selection preferences
+const (
+	// FlavorPreferSmallest orders candidates by vCPUs, RAM and disk
+	FlavorPreferSmallest = "smallest"
+	// FlavorPreferCheapest orders candidates by RAM and vCPUs, as RAM is the main price factor
+	// There is no price information available in the API, so this is only an approximation
+	FlavorPreferCheapest = "cheapest"
+
+	flavorStatusSpec = "cond:operation:status"
+	flavorAZSpec     = "cond:operation:az"
+)
+
+// FlavorConstraints describes flavor requirements, empty fields are ignored
+type FlavorConstraints struct {
+	MinVCPUs         int
+	MinRAM           int    // MB
+	MinDisk          int    // GB
+	Family           string // flavor name prefix, e.g. `s2` or `c3`
+	AvailabilityZone string
+	Preference       string // one of FlavorPrefer const, FlavorPreferSmallest by default
+}
+
+func (fc *FlavorConstraints) match(flavor flavors.Flavor) bool {
+	if flavor.VCPUs < fc.MinVCPUs || flavor.RAM < fc.MinRAM || flavor.Disk < fc.MinDisk {
+		return false
+	}
+	if fc.Family != "" && !strings.HasPrefix(flavor.Name, strings.TrimSuffix(fc.Family, ".")+".") {
+		return false
+	}
+	return true
+}
+
+func (fc *FlavorConstraints) less(a, b flavors.Flavor) bool {
+	keysA := []int{a.VCPUs, a.RAM, a.Disk}
+	keysB := []int{b.VCPUs, b.RAM, b.Disk}
+	if fc.Preference == FlavorPreferCheapest {
+		keysA = []int{a.RAM, a.VCPUs, a.Disk}
+		keysB = []int{b.RAM, b.VCPUs, b.Disk}
+	}
+	for i := range keysA {
+		if keysA[i] != keysB[i] {
+			return keysA[i] < keysB[i]
+		}
+	}
+	return a.Name < b.Name
+}
+
+// flavorAvailableInAZ checks OTC flavor extra specs for flavor status in given AZ
+// AZ status, e.g. `eu-de-01(normal),eu-de-02(abandon)`, overrides common flavor status
+func flavorAvailableInAZ(specs map[string]string, az string) bool {
+	status := specs[flavorStatusSpec]
+	if status == "" {
+		status = "normal"
+	}
+	for _, azStatus := range strings.Split(specs[flavorAZSpec], ",") {
+		name := strings.TrimSpace(azStatus)
+		idx := strings.Index(name, "(")
+		if idx < 0 || !strings.HasSuffix(name, ")") {
+			continue
+		}
+		if name[:idx] == az {
+			status = name[idx+1 : len(name)-1]
+			break
+		}
+	}
+	return status == "normal" || status == "promotion"
+}
+
+// SelectFlavor returns the best flavor matching the constraints and all matching candidates
+// ordered by preference
+func (c *Client) SelectFlavor(constraints *FlavorConstraints) (*flavors.Flavor, []flavors.Flavor, error) {
+	page, err := flavors.ListDetail(c.ComputeV2, nil).AllPages()
+	if err != nil {
+		return nil, nil, err
+	}
+	flavorList, err := flavors.ExtractFlavors(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var candidates []flavors.Flavor
+	for _, flavor := range flavorList {
+		if !constraints.match(flavor) {
+			continue
+		}
+		if constraints.AvailabilityZone != "" {
+			specs, err := flavors.ListExtraSpecs(c.ComputeV2, flavor.ID).Extract()
+			if err != nil {
+				return nil, nil, fmt.Errorf("error getting flavor `%s` extra specs: %s", flavor.Name, err)
+			}
+			if !flavorAvailableInAZ(specs, constraints.AvailabilityZone) {
+				continue
+			}
+		}
+		candidates = append(candidates, flavor)
+	}
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("no flavor matches given constraints")
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return constraints.less(candidates[i], candidates[j])
+	})
+	return &candidates[0], candidates, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/flavors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlavorConstraints_Match(t *testing.T) {
+	flavor := flavors.Flavor{Name: "s2.large.2", VCPUs: 2, RAM: 4096}
+
+	assert.True(t, (&FlavorConstraints{MinVCPUs: 2, MinRAM: 4096, Family: "s2"}).match(flavor))
+	assert.True(t, (&FlavorConstraints{Family: "s2."}).match(flavor))
+	assert.False(t, (&FlavorConstraints{Family: "s"}).match(flavor))
+	assert.False(t, (&FlavorConstraints{MinVCPUs: 4}).match(flavor))
+	assert.False(t, (&FlavorConstraints{MinRAM: 8192}).match(flavor))
+}
+
+func TestFlavorConstraints_Less(t *testing.T) {
+	moreCPU := flavors.Flavor{Name: "c3.large.2", VCPUs: 4, RAM: 4096}
+	moreRAM := flavors.Flavor{Name: "m3.large.8", VCPUs: 2, RAM: 16384}
+
+	assert.True(t, (&FlavorConstraints{}).less(moreRAM, moreCPU))
+	assert.True(t, (&FlavorConstraints{Preference: FlavorPreferCheapest}).less(moreCPU, moreRAM))
+}
+
+func TestFlavorAvailableInAZ(t *testing.T) {
+	specs := map[string]string{
+		flavorStatusSpec: "normal",
+		flavorAZSpec:     "eu-de-01(normal), eu-de-02(abandon),eu-de-03(sellout)",
+	}
+	assert.True(t, flavorAvailableInAZ(specs, "eu-de-01"))
+	assert.False(t, flavorAvailableInAZ(specs, "eu-de-02"))
+	assert.False(t, flavorAvailableInAZ(specs, "eu-de-03"))
+	assert.True(t, flavorAvailableInAZ(specs, "eu-nl-01"))
+	assert.True(t, flavorAvailableInAZ(nil, "eu-de-01"))
+	assert.False(t, flavorAvailableInAZ(map[string]string{flavorStatusSpec: "abandon"}, "eu-de-01"))
+}
+
+func TestClient_SelectFlavor(t *testing.T) {
+	client := computeClient(t)
+	best, candidates, err := client.SelectFlavor(&FlavorConstraints{
+		MinVCPUs:         2,
+		MinRAM:           4096,
+		Family:           "s2",
+		AvailabilityZone: defaultAZ,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, defaultFlavor, best.Name)
+}
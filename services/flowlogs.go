@@ -0,0 +1,135 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/flowlogs"
+)
+
+// Flow log resource types
+const (
+	FlowLogResourceVPC    = "vpc"
+	FlowLogResourceSubnet = "network"
+	FlowLogResourcePort   = "port"
+)
+
+// Flow log traffic types
+const (
+	FlowLogTrafficAll    = "all"
+	FlowLogTrafficAccept = "accept"
+	FlowLogTrafficReject = "reject"
+)
+
+// InitFlowLogs initializes VPC flow logs service
+// It should be called before using flow log methods
+func (c *Client) InitFlowLogs() error {
+	if c.FlowLogs != nil {
+		return nil
+	}
+	fl, err := c.NewServiceClient("flowlogs")
+	if err != nil {
+		return err
+	}
+	c.FlowLogs = fl
+	return nil
+}
+
+// FlowLogOpts contains flow log parameters, log group and topic are required
+type FlowLogOpts struct {
+	Name        string
+	Description string
+	TrafficType string // FlowLogTrafficAll by default
+	LogGroupID  string
+	LogTopicID  string
+}
+
+func validateFlowLogOpts(resourceType string, opts *FlowLogOpts) error {
+	switch resourceType {
+	case FlowLogResourceVPC, FlowLogResourceSubnet, FlowLogResourcePort:
+	default:
+		return fmt.Errorf("unknown flow log resource type `%s`", resourceType)
+	}
+	switch opts.TrafficType {
+	case FlowLogTrafficAll, FlowLogTrafficAccept, FlowLogTrafficReject:
+	default:
+		return fmt.Errorf("unknown flow log traffic type `%s`", opts.TrafficType)
+	}
+	if opts.LogGroupID == "" || opts.LogTopicID == "" {
+		return fmt.Errorf("both log group and log topic are required for flow log")
+	}
+	return nil
+}
+
+// ListFlowLogs returns flow logs of given resource, all flow logs are returned if `resourceID` is empty
+func (c *Client) ListFlowLogs(resourceType, resourceID string) ([]flowlogs.FlowLog, error) {
+	opts := flowlogs.ListOpts{ResourceType: resourceType, ResourceID: resourceID}
+	pages, err := flowlogs.List(c.FlowLogs, opts).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("error listing flow logs: %s", err)
+	}
+	return flowlogs.ExtractFlowLogs(pages)
+}
+
+// GetFlowLog returns flow log details
+func (c *Client) GetFlowLog(flowLogID string) (*flowlogs.FlowLog, error) {
+	return flowlogs.Get(c.FlowLogs, flowLogID).Extract()
+}
+
+// EnableFlowLog starts logging traffic of VPC, subnet or port into given log group and topic
+// Existing flow log of the resource with the same traffic type and target is re-enabled instead of creating a new one
+func (c *Client) EnableFlowLog(resourceType, resourceID string, opts *FlowLogOpts) (*flowlogs.FlowLog, error) {
+	if opts.TrafficType == "" {
+		opts.TrafficType = FlowLogTrafficAll
+	}
+	if err := validateFlowLogOpts(resourceType, opts); err != nil {
+		return nil, err
+	}
+	existing, err := c.ListFlowLogs(resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, fl := range existing {
+		if fl.TrafficType != opts.TrafficType || fl.LogGroupID != opts.LogGroupID || fl.LogTopicID != opts.LogTopicID {
+			continue
+		}
+		if fl.AdminState {
+			return &fl, nil
+		}
+		return c.setFlowLogState(fl.ID, true)
+	}
+	fl, err := flowlogs.Create(c.FlowLogs, flowlogs.CreateOpts{
+		Name:         opts.Name,
+		Description:  opts.Description,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		TrafficType:  opts.TrafficType,
+		LogGroupID:   opts.LogGroupID,
+		LogTopicID:   opts.LogTopicID,
+	}).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error creating flow log: %s", err)
+	}
+	return fl, nil
+}
+
+func (c *Client) setFlowLogState(flowLogID string, enabled bool) (*flowlogs.FlowLog, error) {
+	fl, err := flowlogs.Update(c.FlowLogs, flowLogID, flowlogs.UpdateOpts{AdminState: enabled}).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error updating flow log `%s`: %s", flowLogID, err)
+	}
+	return fl, nil
+}
+
+// DisableFlowLog stops logging traffic keeping flow log configuration
+func (c *Client) DisableFlowLog(flowLogID string) error {
+	_, err := c.setFlowLogState(flowLogID, false)
+	return err
+}
+
+// DeleteFlowLog removes flow log
+func (c *Client) DeleteFlowLog(flowLogID string) error {
+	if err := flowlogs.Delete(c.FlowLogs, flowLogID).ExtractErr(); err != nil {
+		return fmt.Errorf("error deleting flow log `%s`: %s", flowLogID, err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/flowlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const flowLogsPath = "/v1/project-id/fl/flow_logs"
+
+// flowLogStub is in-memory implementation of VPC flow logs API
+type flowLogStub struct {
+	mu    sync.Mutex
+	logs  []flowlogs.FlowLog
+	count int
+}
+
+func (s *flowLogStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, flowLogsPath), "/")
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		query := r.URL.Query()
+		result := make([]flowlogs.FlowLog, 0)
+		for _, fl := range s.logs {
+			if rt := query.Get("resource_type"); rt != "" && rt != fl.ResourceType {
+				continue
+			}
+			if rid := query.Get("resource_id"); rid != "" && rid != fl.ResourceID {
+				continue
+			}
+			result = append(result, fl)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"flow_logs": result})
+	case r.Method == http.MethodPost && id == "":
+		var body struct {
+			FlowLog flowlogs.FlowLog `json:"flow_log"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		s.count++
+		fl := body.FlowLog
+		fl.ID = fmt.Sprintf("flow-log-%d", s.count)
+		fl.AdminState = true
+		fl.Status = "ACTIVE"
+		s.logs = append(s.logs, fl)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"flow_log": fl})
+	default:
+		for i, fl := range s.logs {
+			if fl.ID != id {
+				continue
+			}
+			switch r.Method {
+			case http.MethodGet:
+				writeJSON(w, http.StatusOK, map[string]interface{}{"flow_log": fl})
+			case http.MethodPut:
+				var body struct {
+					FlowLog struct {
+						AdminState bool `json:"admin_state"`
+					} `json:"flow_log"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+					return
+				}
+				s.logs[i].AdminState = body.FlowLog.AdminState
+				s.logs[i].Status = "DOWN"
+				if body.FlowLog.AdminState {
+					s.logs[i].Status = "ACTIVE"
+				}
+				writeJSON(w, http.StatusOK, map[string]interface{}{"flow_log": s.logs[i]})
+			case http.MethodDelete:
+				s.logs = append(s.logs[:i], s.logs[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// flowLogClient returns client using stub flow logs API
+func flowLogClient(t *testing.T) *Client {
+	server := httptest.NewServer(&flowLogStub{})
+	t.Cleanup(server.Close)
+	return &Client{
+		FlowLogs: &golangsdk.ServiceClient{
+			ProviderClient: &golangsdk.ProviderClient{ProjectID: "project-id"},
+			Endpoint:       server.URL + "/",
+			ResourceBase:   server.URL + "/v1/",
+		},
+	}
+}
+
+var flowLogOpts = FlowLogOpts{
+	Name:       "crutch-flow-log",
+	LogGroupID: "log-group-id",
+	LogTopicID: "log-topic-id",
+}
+
+func TestClient_EnableFlowLog(t *testing.T) {
+	client := flowLogClient(t)
+
+	opts := flowLogOpts
+	fl, err := client.EnableFlowLog(FlowLogResourceVPC, "vpc-id", &opts)
+	require.NoError(t, err)
+	assert.Equal(t, FlowLogTrafficAll, fl.TrafficType)
+	assert.Equal(t, FlowLogResourceVPC, fl.ResourceType)
+	assert.Equal(t, "log-topic-id", fl.LogTopicID)
+	assert.True(t, fl.AdminState)
+
+	same, err := client.EnableFlowLog(FlowLogResourceVPC, "vpc-id", &opts)
+	require.NoError(t, err)
+	assert.Equal(t, fl.ID, same.ID)
+
+	opts.TrafficType = FlowLogTrafficReject
+	rejected, err := client.EnableFlowLog(FlowLogResourcePort, "port-id", &opts)
+	require.NoError(t, err)
+	assert.NotEqual(t, fl.ID, rejected.ID)
+
+	vpcLogs, err := client.ListFlowLogs(FlowLogResourceVPC, "vpc-id")
+	require.NoError(t, err)
+	require.Len(t, vpcLogs, 1)
+	assert.Equal(t, fl.ID, vpcLogs[0].ID)
+
+	allLogs, err := client.ListFlowLogs("", "")
+	require.NoError(t, err)
+	assert.Len(t, allLogs, 2)
+}
+
+func TestClient_EnableFlowLogInvalid(t *testing.T) {
+	client := flowLogClient(t)
+
+	opts := flowLogOpts
+	_, err := client.EnableFlowLog("router", "router-id", &opts)
+	assert.Error(t, err)
+
+	opts.TrafficType = "dropped"
+	_, err = client.EnableFlowLog(FlowLogResourceSubnet, "subnet-id", &opts)
+	assert.Error(t, err)
+
+	_, err = client.EnableFlowLog(FlowLogResourceSubnet, "subnet-id", &FlowLogOpts{LogGroupID: "log-group-id"})
+	assert.Error(t, err)
+
+	allLogs, err := client.ListFlowLogs("", "")
+	require.NoError(t, err)
+	assert.Empty(t, allLogs)
+}
+
+func TestClient_DisableFlowLog(t *testing.T) {
+	client := flowLogClient(t)
+
+	opts := flowLogOpts
+	fl, err := client.EnableFlowLog(FlowLogResourceSubnet, "subnet-id", &opts)
+	require.NoError(t, err)
+
+	require.NoError(t, client.DisableFlowLog(fl.ID))
+	disabled, err := client.GetFlowLog(fl.ID)
+	require.NoError(t, err)
+	assert.False(t, disabled.AdminState)
+
+	enabled, err := client.EnableFlowLog(FlowLogResourceSubnet, "subnet-id", &opts)
+	require.NoError(t, err)
+	assert.Equal(t, fl.ID, enabled.ID)
+	assert.True(t, enabled.AdminState)
+
+	require.NoError(t, client.DeleteFlowLog(fl.ID))
+	_, err = client.GetFlowLog(fl.ID)
+	assert.IsType(t, golangsdk.ErrDefault404{}, err)
+	assert.Error(t, client.DisableFlowLog(fl.ID))
+}
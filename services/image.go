@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/imageservice/v2/images"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/imageservice/v2/members"
+)
+
+const imageSaveTimeout = 30 * 60
+
+// InitImage initializes Image v2 service
+func (c *Client) InitImage() error {
+	if c.ImageV2 != nil {
+		return nil
+	}
+	img, err := c.NewServiceClient("image")
+	if err != nil {
+		return err
+	}
+	c.ImageV2 = img
+	return nil
+}
+
+// CreateImageFromInstance captures image of existing ECS instance and returns image ID
+// Instance is stopped before capturing if `stopInstance` is set and started again once capturing is finished or failed
+func (c *Client) CreateImageFromInstance(instanceID string, name string, metadata map[string]string, stopInstance bool) (string, error) {
+	if !stopInstance {
+		return c.captureImage(instanceID, name, metadata)
+	}
+	if err := c.StopInstance(instanceID); err != nil {
+		return "", fmt.Errorf("error stopping instance `%s`: %s", instanceID, err)
+	}
+	var imageID string
+	mErr := &multierror.Error{}
+	if err := c.WaitForInstanceStatus(instanceID, InstanceStatusStopped); err != nil {
+		mErr = multierror.Append(mErr, fmt.Errorf("error waiting for instance `%s` to stop: %s", instanceID, err))
+	} else {
+		id, err := c.captureImage(instanceID, name, metadata)
+		imageID = id
+		mErr = multierror.Append(mErr, err)
+	}
+	if err := c.StartInstance(instanceID); err != nil {
+		mErr = multierror.Append(mErr, fmt.Errorf("error starting instance `%s`: %s", instanceID, err))
+	} else if err := c.WaitForInstanceStatus(instanceID, InstanceStatusRunning); err != nil {
+		mErr = multierror.Append(mErr, fmt.Errorf("error waiting for instance `%s` to start: %s", instanceID, err))
+	}
+	return imageID, mErr.ErrorOrNil()
+}
+
+func (c *Client) captureImage(instanceID string, name string, metadata map[string]string) (string, error) {
+	opts := servers.CreateImageOpts{
+		Name:     name,
+		Metadata: metadata,
+	}
+	imageID, err := servers.CreateImage(c.ComputeV2, instanceID, opts).ExtractImageID()
+	if err != nil {
+		return "", fmt.Errorf("error creating image from instance `%s`: %s", instanceID, err)
+	}
+	if err := c.WaitForImageStatus(imageID, images.ImageStatusActive); err != nil {
+		return imageID, err
+	}
+	return imageID, nil
+}
+
+// GetImageStatus returns image details by image ID
+func (c *Client) GetImageStatus(imageID string) (*images.Image, error) {
+	return images.Get(c.ImageV2, imageID).Extract()
+}
+
+// WaitForImageStatus waits for image to be in given status
+func (c *Client) WaitForImageStatus(imageID string, status images.ImageStatus) error {
+	return golangsdk.WaitFor(imageSaveTimeout, func() (bool, error) {
+		img, err := c.GetImageStatus(imageID)
+		if err != nil {
+			return true, err
+		}
+		if img.Status == images.ImageStatusKilled {
+			return true, fmt.Errorf("image `%s` is in %s state", imageID, img.Status)
+		}
+		return img.Status == status, nil
+	})
+}
+
+// DeleteImage removes existing image
+func (c *Client) DeleteImage(imageID string) error {
+	return images.Delete(c.ImageV2, imageID).Err
+}
+
+// ShareImage shares private image with given project
+func (c *Client) ShareImage(imageID string, projectID string) (*members.Member, error) {
+	opts := members.CreateOpts{Member: projectID}
+	return members.Create(c.ImageV2, imageID, opts).Extract()
+}
+
+// UnshareImage stops sharing image with given project
+func (c *Client) UnshareImage(imageID string, projectID string) error {
+	return members.Delete(c.ImageV2, imageID, projectID).Err
+}
+
+// AcceptImage accepts image shared with given project
+// This should be called by the project receiving the image
+func (c *Client) AcceptImage(imageID string, projectID string) error {
+	opts := members.UpdateOpts{Status: string(images.ImageMemberStatusAccepted)}
+	return members.Update(c.ImageV2, imageID, projectID, opts).Err
+}
+
+// ListImageMembers returns all projects given image is shared with
+func (c *Client) ListImageMembers(imageID string) ([]members.Member, error) {
+	page, err := members.List(c.ImageV2, imageID).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	return members.ExtractMembers(page)
+}
+
+const (
+	imagePlatformProperty  = "__platform"
+	imageOSVersionProperty = "__os_version"
+)
+
+// ImageConstraints describes image requirements, empty fields are ignored
+type ImageConstraints struct {
+	NameRegex  string
+	Platform   string // OS distribution, e.g. `Debian`
+	OSVersion  string // regex matching OS version, e.g. `Debian GNU/Linux 10`
+	Visibility images.ImageVisibility
+	DiskSize   int  // GB, images requiring bigger disk are skipped
+	Latest     bool // select newest image if multiple images match
+}
+
+func imageProperty(image images.Image, name string) string {
+	value, _ := image.Properties[name].(string)
+	return value
+}
+
+// selectImage returns the only matching image or the newest one if `Latest` is set
+func selectImage(imageList []images.Image, constraints *ImageConstraints) (*images.Image, error) {
+	var nameRe, versionRe *regexp.Regexp
+	var err error
+	if constraints.NameRegex != "" {
+		if nameRe, err = regexp.Compile(constraints.NameRegex); err != nil {
+			return nil, fmt.Errorf("invalid image name regex: %s", err)
+		}
+	}
+	if constraints.OSVersion != "" {
+		if versionRe, err = regexp.Compile(constraints.OSVersion); err != nil {
+			return nil, fmt.Errorf("invalid OS version regex: %s", err)
+		}
+	}
+
+	var candidates []images.Image
+	for _, image := range imageList {
+		if image.Status != images.ImageStatusActive {
+			continue
+		}
+		if nameRe != nil && !nameRe.MatchString(image.Name) {
+			continue
+		}
+		if constraints.Platform != "" && !strings.EqualFold(imageProperty(image, imagePlatformProperty), constraints.Platform) {
+			continue
+		}
+		if versionRe != nil && !versionRe.MatchString(imageProperty(image, imageOSVersionProperty)) {
+			continue
+		}
+		if constraints.Visibility != "" && image.Visibility != constraints.Visibility {
+			continue
+		}
+		if constraints.DiskSize != 0 && image.MinDiskGigabytes > constraints.DiskSize {
+			continue
+		}
+		candidates = append(candidates, image)
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no image matches given constraints")
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].CreatedAt.After(candidates[j].CreatedAt)
+	})
+	ambiguous := len(candidates) > 1
+	if constraints.Latest {
+		ambiguous = ambiguous && candidates[0].CreatedAt.Equal(candidates[1].CreatedAt)
+	}
+	if ambiguous {
+		found := make([]string, len(candidates))
+		for i, image := range candidates {
+			found[i] = fmt.Sprintf("%s (%s)", image.Name, image.ID)
+		}
+		return nil, fmt.Errorf("multiple images match given constraints: %s", strings.Join(found, ", "))
+	}
+	return &candidates[0], nil
+}
+
+// SelectImage returns image matching the constraints
+// Error is returned if multiple images match and the newest one can't be selected
+func (c *Client) SelectImage(constraints *ImageConstraints) (*images.Image, error) {
+	opts := images.ListOpts{
+		Status:     images.ImageStatusActive,
+		Visibility: constraints.Visibility,
+	}
+	page, err := images.List(c.ImageV2, opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	imageList, err := images.ExtractImages(page)
+	if err != nil {
+		return nil, err
+	}
+	return selectImage(imageList, constraints)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/imageservice/v2/images"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentelekomcloud-infra/crutch-house/utils"
+)
+
+func TestClient_CreateImageFromInstance(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitImage())
+
+	instance, cleanup := createTestInstance(t, client)
+	defer cleanup()
+
+	imageName := utils.RandomString(12, "img-")
+	imageID, err := client.CreateImageFromInstance(instance.ID, imageName, map[string]string{"by": "crutch"}, true)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, client.DeleteImage(imageID)) }()
+
+	image, err := client.GetImageStatus(imageID)
+	require.NoError(t, err)
+	assert.Equal(t, images.ImageStatusActive, image.Status)
+	assert.Equal(t, imageName, image.Name)
+
+	details, err := client.GetInstanceStatus(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, InstanceStatusRunning, details.Status)
+
+	found, err := client.FindImage(imageName)
+	assert.NoError(t, err)
+	assert.Equalf(t, imageID, found, invalidFind, "image")
+}
+
+func testImage(name string, created time.Time, platform string) images.Image {
+	return images.Image{
+		ID:               name + "-id",
+		Name:             name,
+		Status:           images.ImageStatusActive,
+		Visibility:       images.ImageVisibilityPublic,
+		MinDiskGigabytes: 4,
+		CreatedAt:        created,
+		Properties: map[string]interface{}{
+			imagePlatformProperty:  platform,
+			imageOSVersionProperty: platform + " 10.0.0 64bit",
+		},
+	}
+}
+
+func TestSelectImage(t *testing.T) {
+	now := time.Now()
+	imageList := []images.Image{
+		testImage("Standard_Debian_10_old", now.Add(-time.Hour), "Debian"),
+		testImage("Standard_Debian_10_latest", now, "Debian"),
+		testImage("Standard_CentOS_7_latest", now, "CentOS"),
+	}
+
+	_, err := selectImage(imageList, &ImageConstraints{Platform: "debian"})
+	assert.Error(t, err, "ambiguous result expected")
+
+	image, err := selectImage(imageList, &ImageConstraints{Platform: "debian", Latest: true})
+	require.NoError(t, err)
+	assert.Equal(t, "Standard_Debian_10_latest", image.Name)
+
+	image, err = selectImage(imageList, &ImageConstraints{NameRegex: "^Standard_Debian_10_", OSVersion: "^Debian 10", Latest: true})
+	require.NoError(t, err)
+	assert.Equal(t, "Standard_Debian_10_latest", image.Name)
+
+	_, err = selectImage(imageList, &ImageConstraints{NameRegex: "_latest$", Latest: true})
+	assert.Error(t, err, "ambiguous result expected for images with same creation date")
+
+	_, err = selectImage(imageList, &ImageConstraints{Platform: "CentOS", DiskSize: 2})
+	assert.Error(t, err)
+
+	_, err = selectImage(imageList, &ImageConstraints{Visibility: images.ImageVisibilityPrivate})
+	assert.Error(t, err)
+}
+
+func TestClient_SelectImage(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitImage())
+
+	image, err := client.SelectImage(&ImageConstraints{
+		NameRegex:  "^Standard_Debian_10_",
+		Visibility: images.ImageVisibilityPublic,
+		DiskSize:   10,
+		Latest:     true,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, image.ID)
+}
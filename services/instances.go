@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+	"github.com/opentelekomcloud/gophertelekomcloud/pagination"
+)
+
+// InstanceFilter contains instance list filters, empty fields are ignored
+type InstanceFilter struct {
+	NameRegex     string
+	Status        string
+	FlavorID      string
+	ImageID       string
+	Tags          []string
+	AnyTag        bool     // match instances having any of Tags instead of all of them
+	MetadataKeys  []string // instances should have all given metadata keys
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// InstanceSummary is short description of the instance
+type InstanceSummary struct {
+	ID       string
+	Name     string
+	Status   string
+	FlavorID string
+	ImageID  string
+	Created  time.Time
+	Metadata map[string]string
+	Tags     []string // set only if instances are filtered by tags
+}
+
+func summarize(server *servers.Server) InstanceSummary {
+	summary := InstanceSummary{
+		ID:       server.ID,
+		Name:     server.Name,
+		Status:   server.Status,
+		Created:  server.Created,
+		Metadata: server.Metadata,
+	}
+	if id, ok := server.Flavor["id"].(string); ok {
+		summary.FlavorID = id
+	}
+	if id, ok := server.Image["id"].(string); ok {
+		summary.ImageID = id
+	}
+	return summary
+}
+
+// matchServer checks all filters not requiring additional requests
+func (f *InstanceFilter) matchServer(summary InstanceSummary, nameRe *regexp.Regexp) bool {
+	if nameRe != nil && !nameRe.MatchString(summary.Name) {
+		return false
+	}
+	if f.Status != "" && summary.Status != f.Status {
+		return false
+	}
+	if f.FlavorID != "" && summary.FlavorID != f.FlavorID {
+		return false
+	}
+	if f.ImageID != "" && summary.ImageID != f.ImageID {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !summary.Created.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !summary.Created.Before(f.CreatedBefore) {
+		return false
+	}
+	for _, key := range f.MetadataKeys {
+		if _, ok := summary.Metadata[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matchTags checks if instance tags satisfy the filter
+func (f *InstanceFilter) matchTags(instanceTags []string) bool {
+	present := make(map[string]bool, len(instanceTags))
+	for _, tag := range instanceTags {
+		present[tag] = true
+	}
+	for _, tag := range f.Tags {
+		if present[tag] && f.AnyTag {
+			return true
+		}
+		if !present[tag] && !f.AnyTag {
+			return false
+		}
+	}
+	return !f.AnyTag
+}
+
+// ListInstances returns summaries of all instances matching the filter
+func (c *Client) ListInstances(filter *InstanceFilter) ([]InstanceSummary, error) {
+	if filter == nil {
+		filter = &InstanceFilter{}
+	}
+	var nameRe *regexp.Regexp
+	if filter.NameRegex != "" {
+		re, err := regexp.Compile(filter.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid instance name regex: %s", err)
+		}
+		nameRe = re
+	}
+
+	listOpts := servers.ListOpts{
+		Status: filter.Status,
+		Flavor: filter.FlavorID,
+		Image:  filter.ImageID,
+	}
+	var result []InstanceSummary
+	err := servers.List(c.ComputeV2, listOpts).EachPage(func(page pagination.Page) (bool, error) {
+		servs, err := servers.ExtractServers(page)
+		if err != nil {
+			return false, err
+		}
+		for i := range servs {
+			summary := summarize(&servs[i])
+			if filter.matchServer(summary, nameRe) {
+				result = append(result, summary)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(filter.Tags) == 0 {
+		return result, nil
+	}
+
+	tagged := make([]InstanceSummary, 0, len(result))
+	for _, summary := range result {
+		instanceTags, err := c.ListTags(summary.ID)
+		if err != nil {
+			return nil, err
+		}
+		if filter.matchTags(instanceTags) {
+			summary.Tags = instanceTags
+			tagged = append(tagged, summary)
+		}
+	}
+	return tagged, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceFilter_MatchServer(t *testing.T) {
+	created := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	summary := InstanceSummary{
+		Name:     "node-01",
+		Status:   InstanceStatusRunning,
+		FlavorID: defaultFlavor,
+		Created:  created,
+		Metadata: map[string]string{"owner": "ci"},
+	}
+	nameRe := regexp.MustCompile(`^node-\d+$`)
+
+	assert.True(t, (&InstanceFilter{}).matchServer(summary, nil))
+	assert.True(t, (&InstanceFilter{
+		Status:       InstanceStatusRunning,
+		FlavorID:     defaultFlavor,
+		MetadataKeys: []string{"owner"},
+		CreatedAfter: created.Add(-time.Hour),
+	}).matchServer(summary, nameRe))
+
+	assert.False(t, (&InstanceFilter{}).matchServer(summary, regexp.MustCompile(`^master`)))
+	assert.False(t, (&InstanceFilter{Status: InstanceStatusStopped}).matchServer(summary, nil))
+	assert.False(t, (&InstanceFilter{MetadataKeys: []string{"ttl"}}).matchServer(summary, nil))
+	assert.False(t, (&InstanceFilter{CreatedBefore: created}).matchServer(summary, nil))
+}
+
+func TestInstanceFilter_MatchTags(t *testing.T) {
+	instanceTags := []string{"ci", "debian"}
+
+	assert.True(t, (&InstanceFilter{Tags: []string{"ci", "debian"}}).matchTags(instanceTags))
+	assert.False(t, (&InstanceFilter{Tags: []string{"ci", "centos"}}).matchTags(instanceTags))
+	assert.True(t, (&InstanceFilter{Tags: []string{"ci", "centos"}, AnyTag: true}).matchTags(instanceTags))
+	assert.False(t, (&InstanceFilter{Tags: []string{"centos"}, AnyTag: true}).matchTags(instanceTags))
+}
+
+func TestClient_ListInstances(t *testing.T) {
+	client := computeClient(t)
+
+	instance, cleanup := createTestInstance(t, client)
+	defer cleanup()
+
+	require.NoError(t, client.AddTags(instance.ID, []string{"crutch", "test"}))
+
+	found, err := client.ListInstances(&InstanceFilter{
+		NameRegex: "^" + regexp.QuoteMeta(serverName) + "$",
+		Tags:      []string{"crutch"},
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, instance.ID, found[0].ID)
+	assert.ElementsMatch(t, []string{"crutch", "test"}, found[0].Tags)
+
+	require.NoError(t, client.DeleteTag(instance.ID, "test"))
+	instanceTags, err := client.ListTags(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"crutch"}, instanceTags)
+
+	require.NoError(t, client.DeleteTags(instance.ID))
+	found, err = client.ListInstances(&InstanceFilter{Tags: []string{"crutch"}})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/dnatrules"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/natgateways"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/snatrules"
+)
+
+// NAT gateway specifications
+const (
+	NATGatewaySmall  = "1"
+	NATGatewayMedium = "2"
+	NATGatewayLarge  = "3"
+	NATGatewayXLarge = "4"
+)
+
+// DNAT rule protocols
+const (
+	DNATProtocolTCP = "tcp"
+	DNATProtocolUDP = "udp"
+	DNATProtocolAny = "any"
+)
+
+const (
+	natStatusActive = "ACTIVE"
+	natStatusError  = "ERROR"
+	natTimeout      = 600
+)
+
+// InitNAT initializes NAT v2 service
+// It should be called before using NAT gateway and NAT rule methods
+func (c *Client) InitNAT() error {
+	if c.NatV2 != nil {
+		return nil
+	}
+	nat, err := c.NewServiceClient("nat")
+	if err != nil {
+		return err
+	}
+	c.NatV2 = nat
+	return nil
+}
+
+// waitForNATStatus waits until NAT resource is in given status, empty status means waiting until resource is deleted
+func waitForNATStatus(resource string, getStatus func() (string, error), status string) error {
+	return golangsdk.WaitFor(natTimeout, func() (bool, error) {
+		current, err := getStatus()
+		if err != nil {
+			if _, ok := err.(golangsdk.ErrDefault404); ok && status == "" {
+				return true, nil
+			}
+			return true, err
+		}
+		if current == natStatusError {
+			return true, fmt.Errorf("%s is in %s state", resource, current)
+		}
+		return current == status, nil
+	})
+}
+
+// NATGatewayOpts contains optional NAT gateway parameters
+type NATGatewayOpts struct {
+	Description string
+	Spec        string // NATGatewaySmall by default
+}
+
+// CreateNATGateway creates NAT gateway in VPC subnet and waits for it to become active, `opts` can be nil
+// VPC subnet ID should be used as `subnetID`
+func (c *Client) CreateNATGateway(name, vpcID, subnetID string, opts *NATGatewayOpts) (*natgateways.NatGateway, error) {
+	if opts == nil {
+		opts = &NATGatewayOpts{}
+	}
+	spec := opts.Spec
+	if spec == "" {
+		spec = NATGatewaySmall
+	}
+	gateway, err := natgateways.Create(c.NatV2, natgateways.CreateOpts{
+		Name:              name,
+		Description:       opts.Description,
+		Spec:              spec,
+		RouterID:          vpcID,
+		InternalNetworkID: subnetID,
+	}).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error creating NAT gateway: %s", err)
+	}
+	if err := c.WaitForNATGatewayStatus(gateway.ID, natStatusActive); err != nil {
+		return &gateway, err
+	}
+	return c.GetNATGateway(gateway.ID)
+}
+
+// GetNATGateway returns NAT gateway details
+func (c *Client) GetNATGateway(gatewayID string) (*natgateways.NatGateway, error) {
+	gateway, err := natgateways.Get(c.NatV2, gatewayID).Extract()
+	if err != nil {
+		return nil, err
+	}
+	return &gateway, nil
+}
+
+// FindNATGateway find NAT gateway by its name and return NAT gateway ID
+func (c *Client) FindNATGateway(name string) (string, error) {
+	pages, err := natgateways.List(c.NatV2, natgateways.ListOpts{Name: name}).AllPages()
+	if err != nil {
+		return "", err
+	}
+	gateways, err := natgateways.ExtractNatGateways(pages)
+	if err != nil {
+		return "", err
+	}
+	if len(gateways) == 0 {
+		return "", nil
+	}
+	if len(gateways) > 1 {
+		return "", fmt.Errorf("multiple NAT gateways found by name %s. Please provide NAT gateway ID instead", name)
+	}
+	return gateways[0].ID, nil
+}
+
+// WaitForNATGatewayStatus waits until NAT gateway is in given status, empty status means waiting until it is deleted
+func (c *Client) WaitForNATGatewayStatus(gatewayID, status string) error {
+	return waitForNATStatus(fmt.Sprintf("NAT gateway `%s`", gatewayID), func() (string, error) {
+		gateway, err := c.GetNATGateway(gatewayID)
+		if err != nil {
+			return "", err
+		}
+		return gateway.Status, nil
+	}, status)
+}
+
+// DeleteNATGateway removes NAT gateway and waits until it is deleted
+// All SNAT and DNAT rules of the gateway should be removed first
+func (c *Client) DeleteNATGateway(gatewayID string) error {
+	if err := natgateways.Delete(c.NatV2, gatewayID).ExtractErr(); err != nil {
+		return fmt.Errorf("error deleting NAT gateway: %s", err)
+	}
+	return c.WaitForNATGatewayStatus(gatewayID, "")
+}
+
+// CreateSNATRule creates SNAT rule translating subnet addresses to EIP and waits for it to become active
+// VPC subnet ID should be used as `subnetID`
+func (c *Client) CreateSNATRule(gatewayID, subnetID, eipID string) (*snatrules.SnatRule, error) {
+	rule, err := snatrules.Create(c.NatV2, snatrules.CreateOpts{
+		NatGatewayID: gatewayID,
+		NetworkID:    subnetID,
+		FloatingIPID: eipID,
+	}).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SNAT rule: %s", err)
+	}
+	if err := c.WaitForSNATRuleStatus(rule.ID, natStatusActive); err != nil {
+		return rule, err
+	}
+	return snatrules.Get(c.NatV2, rule.ID).Extract()
+}
+
+// WaitForSNATRuleStatus waits until SNAT rule is in given status, empty status means waiting until it is deleted
+func (c *Client) WaitForSNATRuleStatus(ruleID, status string) error {
+	return waitForNATStatus(fmt.Sprintf("SNAT rule `%s`", ruleID), func() (string, error) {
+		rule, err := snatrules.Get(c.NatV2, ruleID).Extract()
+		if err != nil {
+			return "", err
+		}
+		return rule.Status, nil
+	}, status)
+}
+
+// DeleteSNATRule removes SNAT rule and waits until it is deleted
+func (c *Client) DeleteSNATRule(ruleID string) error {
+	if err := snatrules.Delete(c.NatV2, ruleID).ExtractErr(); err != nil {
+		return fmt.Errorf("error deleting SNAT rule: %s", err)
+	}
+	return c.WaitForSNATRuleStatus(ruleID, "")
+}
+
+// DNATRuleOpts contains DNAT rule parameters
+// Either PortID, PrivateIP or InstanceID with SubnetID should be set
+type DNATRuleOpts struct {
+	Protocol     string // DNATProtocolTCP by default
+	ExternalPort int
+	InternalPort int
+	PortID       string
+	PrivateIP    string
+	InstanceID   string // port of the instance in SubnetID is used, Neutron client should be initialized
+	SubnetID     string
+}
+
+// CreateDNATRule creates DNAT rule forwarding EIP port to instance port and waits for it to become active
+func (c *Client) CreateDNATRule(gatewayID, eipID string, opts *DNATRuleOpts) (*dnatrules.DnatRule, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("DNAT rule options are required")
+	}
+	protocol := opts.Protocol
+	if protocol == "" {
+		protocol = DNATProtocolTCP
+	}
+	portID := opts.PortID
+	if portID == "" && opts.PrivateIP == "" {
+		if opts.InstanceID == "" {
+			return nil, fmt.Errorf("either port ID, private IP or instance ID is required for DNAT rule")
+		}
+		port, err := c.FindInstancePort(opts.InstanceID, opts.SubnetID)
+		if err != nil {
+			return nil, err
+		}
+		if port == nil {
+			return nil, fmt.Errorf("instance `%s` has no port in subnet `%s`", opts.InstanceID, opts.SubnetID)
+		}
+		portID = port.ID
+	}
+	rule, err := dnatrules.Create(c.NatV2, dnatrules.CreateOpts{
+		NatGatewayID:        gatewayID,
+		PortID:              portID,
+		PrivateIp:           opts.PrivateIP,
+		InternalServicePort: &opts.InternalPort,
+		FloatingIpID:        eipID,
+		ExternalServicePort: &opts.ExternalPort,
+		Protocol:            protocol,
+	}).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error creating DNAT rule: %s", err)
+	}
+	if err := c.WaitForDNATRuleStatus(rule.ID, natStatusActive); err != nil {
+		return rule, err
+	}
+	return dnatrules.Get(c.NatV2, rule.ID).Extract()
+}
+
+// WaitForDNATRuleStatus waits until DNAT rule is in given status, empty status means waiting until it is deleted
+func (c *Client) WaitForDNATRuleStatus(ruleID, status string) error {
+	return waitForNATStatus(fmt.Sprintf("DNAT rule `%s`", ruleID), func() (string, error) {
+		rule, err := dnatrules.Get(c.NatV2, ruleID).Extract()
+		if err != nil {
+			return "", err
+		}
+		return rule.Status, nil
+	}, status)
+}
+
+// DeleteDNATRule removes DNAT rule and waits until it is deleted
+func (c *Client) DeleteDNATRule(ruleID string) error {
+	if err := dnatrules.Delete(c.NatV2, ruleID).ExtractErr(); err != nil {
+		return fmt.Errorf("error deleting DNAT rule: %s", err)
+	}
+	return c.WaitForDNATRuleStatus(ruleID, "")
+}
+
+// ListNATGateways returns all NAT gateways of the VPC
+func (c *Client) ListNATGateways(vpcID string) ([]natgateways.NatGateway, error) {
+	pages, err := natgateways.List(c.NatV2, natgateways.ListOpts{RouterID: vpcID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	return natgateways.ExtractNatGateways(pages)
+}
+
+// natRulesPageLimit is number of NAT rules requested per page
+const natRulesPageLimit = 100
+
+// natRulesURL returns URL of NAT gateway rules page starting after `marker`
+func (c *Client) natRulesURL(resource, gatewayID, marker string) string {
+	query := url.Values{}
+	query.Set("nat_gateway_id", gatewayID)
+	query.Set("limit", strconv.Itoa(natRulesPageLimit))
+	if marker != "" {
+		query.Set("marker", marker)
+	}
+	return c.NatV2.ServiceURL(resource) + "?" + query.Encode()
+}
+
+// ListSNATRules returns all SNAT rules of NAT gateway
+func (c *Client) ListSNATRules(gatewayID string) ([]snatrules.SnatRule, error) {
+	var result []snatrules.SnatRule
+	marker := ""
+	for {
+		var body struct {
+			Rules []snatrules.SnatRule `json:"snat_rules"`
+		}
+		if _, err := c.NatV2.Get(c.natRulesURL("snat_rules", gatewayID, marker), &body, nil); err != nil {
+			return nil, fmt.Errorf("error listing SNAT rules: %s", err)
+		}
+		result = append(result, body.Rules...)
+		if len(body.Rules) < natRulesPageLimit {
+			return result, nil
+		}
+		marker = body.Rules[len(body.Rules)-1].ID
+	}
+}
+
+// ListDNATRules returns all DNAT rules of NAT gateway
+func (c *Client) ListDNATRules(gatewayID string) ([]dnatrules.DnatRule, error) {
+	var result []dnatrules.DnatRule
+	marker := ""
+	for {
+		var body struct {
+			Rules []dnatrules.DnatRule `json:"dnat_rules"`
+		}
+		if _, err := c.NatV2.Get(c.natRulesURL("dnat_rules", gatewayID, marker), &body, nil); err != nil {
+			return nil, fmt.Errorf("error listing DNAT rules: %s", err)
+		}
+		result = append(result, body.Rules...)
+		if len(body.Rules) < natRulesPageLimit {
+			return result, nil
+		}
+		marker = body.Rules[len(body.Rules)-1].ID
+	}
+}
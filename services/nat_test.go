@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/snatrules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_NATGatewayLifecycle(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitNAT())
+	require.NoError(t, client.InitNetworkV2())
+
+	instance, cleanupInstance := createTestInstance(t, client)
+	defer cleanupInstance()
+
+	vpcID, err := client.FindVPC(vpcName)
+	require.NoError(t, err)
+	subnetID, err := client.FindSubnet(vpcID, subnetName)
+	require.NoError(t, err)
+
+	gateway, err := client.CreateNATGateway("test-nat", vpcID, subnetID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, natStatusActive, gateway.Status)
+	assert.Equal(t, NATGatewaySmall, gateway.Spec)
+	defer func() { assert.NoError(t, client.DeleteNATGateway(gateway.ID)) }()
+
+	gatewayID, err := client.FindNATGateway("test-nat")
+	require.NoError(t, err)
+	assert.Equal(t, gateway.ID, gatewayID)
+
+	eip, err := client.CreateEIP(eipOptions)
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForEIPActive(eip.ID))
+	defer func() { assert.NoError(t, client.ReleaseEIP(eip.ID)) }()
+
+	snat, err := client.CreateSNATRule(gateway.ID, subnetID, eip.ID)
+	require.NoError(t, err)
+	assert.Equal(t, eip.PublicAddress, snat.FloatingIPAddress)
+
+	dnat, err := client.CreateDNATRule(gateway.ID, eip.ID, &DNATRuleOpts{
+		ExternalPort: 2222,
+		InternalPort: 22,
+		InstanceID:   instance.ID,
+		SubnetID:     subnetID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DNATProtocolTCP, dnat.Protocol)
+	assert.Equal(t, 2222, dnat.ExternalServicePort)
+
+	assert.NoError(t, client.DeleteDNATRule(dnat.ID))
+	assert.NoError(t, client.DeleteSNATRule(snat.ID))
+}
+
+func TestClient_ListSNATRulesPages(t *testing.T) {
+	const gatewayID = "gateway&id"
+	rules := make([]snatrules.SnatRule, 2*natRulesPageLimit+1)
+	for i := range rules {
+		rules[i] = snatrules.SnatRule{ID: fmt.Sprintf("rule-%03d", i), NatGatewayID: gatewayID}
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := r.URL.Query()
+		if query.Get("nat_gateway_id") != gatewayID {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid gateway ID"})
+			return
+		}
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		start := 0
+		if marker := query.Get("marker"); marker != "" {
+			for i, rule := range rules {
+				if rule.ID == marker {
+					start = i + 1
+				}
+			}
+		}
+		end := start + limit
+		if end > len(rules) {
+			end = len(rules)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"snat_rules": rules[start:end]})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		NatV2: &golangsdk.ServiceClient{
+			ProviderClient: &golangsdk.ProviderClient{},
+			Endpoint:       server.URL + "/",
+			ResourceBase:   server.URL + "/v2.0/",
+		},
+	}
+	result, err := client.ListSNATRules(gatewayID)
+	require.NoError(t, err)
+	require.Len(t, result, len(rules))
+	assert.Equal(t, "rule-200", result[len(result)-1].ID)
+	assert.Equal(t, 3, requests)
+}
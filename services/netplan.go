@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/subnets"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/vpcs"
+
+	"github.com/opentelekomcloud-infra/crutch-house/ipplan"
+)
+
+// ListNetworkLayout returns all existing VPCs with their subnets in the form used by IP planner
+func (c *Client) ListNetworkLayout() ([]ipplan.ExistingVPC, error) {
+	vpcList, err := vpcs.List(c.VPC, vpcs.ListOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing VPCs: %s", err)
+	}
+	subnetList, err := subnets.List(c.VPC, subnets.ListOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing subnets: %s", err)
+	}
+	byVPC := make(map[string][]ipplan.ExistingSubnet)
+	for _, subnet := range subnetList {
+		byVPC[subnet.VpcID] = append(byVPC[subnet.VpcID], ipplan.ExistingSubnet{Name: subnet.Name, CIDR: subnet.CIDR})
+	}
+	layout := make([]ipplan.ExistingVPC, len(vpcList))
+	for i, vpc := range vpcList {
+		layout[i] = ipplan.ExistingVPC{
+			Name:    vpc.Name,
+			CIDR:    vpc.CIDR,
+			Subnets: byVPC[vpc.ID],
+		}
+	}
+	return layout, nil
+}
+
+// PlanNetwork plans VPCs and subnets inside the supernet avoiding conflicts with existing ones
+func (c *Client) PlanNetwork(supernet string, requests []ipplan.VPCRequest) (*ipplan.Plan, error) {
+	layout, err := c.ListNetworkLayout()
+	if err != nil {
+		return nil, err
+	}
+	return ipplan.NewPlan(supernet, requests, layout)
+}
+
+// ApplyNetworkPlan creates planned VPCs and subnets which don't exist yet
+// Returns IDs of all planned VPCs mapped by VPC name
+func (c *Client) ApplyNetworkPlan(plan *ipplan.Plan) (map[string]string, error) {
+	vpcIDs := make(map[string]string, len(plan.VPCs))
+	for _, vpcPlan := range plan.VPCs {
+		vpcID, err := c.applyVPCPlan(vpcPlan)
+		if err != nil {
+			return vpcIDs, err
+		}
+		vpcIDs[vpcPlan.Name] = vpcID
+	}
+	return vpcIDs, nil
+}
+
+func (c *Client) applyVPCPlan(vpcPlan ipplan.VPCAllocation) (string, error) {
+	vpc, _, err := c.EnsureVPC(vpcPlan.Name, &VPCOpts{CIDR: vpcPlan.CIDR})
+	if err != nil {
+		return "", err
+	}
+	for _, subnetPlan := range vpcPlan.Subnets {
+		_, _, err := c.EnsureSubnet(vpc.ID, subnetPlan.Name, &SubnetOpts{
+			CIDR:      subnetPlan.CIDR,
+			GatewayIP: subnetPlan.Gateway,
+		})
+		if err != nil {
+			return vpc.ID, err
+		}
+	}
+	return vpc.ID, nil
+}
@@ -4,16 +4,25 @@ import (
 	"fmt"
 
 	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/attachinterfaces"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/layer3/floatingips"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/portsecurity"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/networks"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
 )
 
-const LBStateActive = "ACTIVE"
+const (
+	LBStateActive = "ACTIVE"
+
+	defaultExternalNetwork = "admin_external_net"
+)
 
 // InitNetworkV2 initializes OpenStack Neutron client
+// It should be called before using methods based on Neutron API
 func (c *Client) InitNetworkV2() error {
 	if c.NetworkV2 != nil {
 		return nil
@@ -81,23 +90,262 @@ func (c *Client) DeleteLoadBalancer(id string) error {
 	return c.waitForLBDeleted(id)
 }
 
+// ListFloatingIPs returns all floating IPs matching given filter
+func (c *Client) ListFloatingIPs(opts floatingips.ListOpts) ([]floatingips.FloatingIP, error) {
+	page, err := floatingips.List(c.NetworkV2, opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	return floatingips.ExtractFloatingIPs(page)
+}
+
+// FindFloatingIPDetails returns floating IP details by its address or nil if it's not found
+func (c *Client) FindFloatingIPDetails(floatingIP string) (*floatingips.FloatingIP, error) {
+	ips, err := c.ListFloatingIPs(floatingips.ListOpts{FloatingIP: floatingIP})
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, nil
+	}
+	return &ips[0], nil
+}
+
+func (c *Client) getExistingFloatingIP(floatingIP string) (*floatingips.FloatingIP, error) {
+	ip, err := c.FindFloatingIPDetails(floatingIP)
+	if err != nil {
+		return nil, err
+	}
+	if ip == nil {
+		return nil, fmt.Errorf("failed to find existing floating IP `%s`", floatingIP)
+	}
+	return ip, nil
+}
+
+// findNetworkID resolves network ID by its name or ID
+func (c *Client) findNetworkID(nameOrID string) (string, error) {
+	page, err := networks.List(c.NetworkV2, networks.ListOpts{Name: nameOrID}).AllPages()
+	if err != nil {
+		return "", err
+	}
+	found, err := networks.ExtractNetworks(page)
+	if err != nil {
+		return "", err
+	}
+	if len(found) > 1 {
+		return "", fmt.Errorf("multiple networks found by name %s. Please provide network ID instead", nameOrID)
+	}
+	if len(found) == 1 {
+		return found[0].ID, nil
+	}
+	network, err := networks.Get(c.NetworkV2, nameOrID).Extract()
+	if err != nil {
+		return "", fmt.Errorf("failed to find network `%s`: %s", nameOrID, err)
+	}
+	return network.ID, nil
+}
+
+// AllocateFloatingIP allocates new floating IP from given external network
+// `admin_external_net` is used if no network name or ID is given
+func (c *Client) AllocateFloatingIP(externalNetwork string) (*floatingips.FloatingIP, error) {
+	if externalNetwork == "" {
+		externalNetwork = defaultExternalNetwork
+	}
+	networkID, err := c.findNetworkID(externalNetwork)
+	if err != nil {
+		return nil, err
+	}
+	return floatingips.Create(c.NetworkV2, floatingips.CreateOpts{
+		FloatingNetworkID: networkID,
+	}).Extract()
+}
+
+// instancePorts returns all network ports of the instance
+func (c *Client) instancePorts(instanceID string) ([]ports.Port, error) {
+	page, err := ports.List(c.NetworkV2, ports.ListOpts{DeviceID: instanceID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	return ports.ExtractPorts(page)
+}
+
+// FindInstancePort returns port of the instance in given subnet or nil if there is no such port
+// Both VPC subnet ID and Neutron subnet ID can be used as `subnetID`
+func (c *Client) FindInstancePort(instanceID, subnetID string) (*ports.Port, error) {
+	instancePorts, err := c.instancePorts(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	for i, port := range instancePorts {
+		if port.NetworkID == subnetID {
+			return &instancePorts[i], nil
+		}
+		for _, ip := range port.FixedIPs {
+			if ip.SubnetID == subnetID {
+				return &instancePorts[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ExtendedPortOpts contains port creation options including port security toggle
+type ExtendedPortOpts struct {
+	*ports.CreateOpts
+	PortSecurityEnabled *bool
+}
+
+// CreatePort creates new networking port
+// `NetworkID` of the port is ID of VPC subnet, `FixedIPs` should be a slice of ports.IP
+func (c *Client) CreatePort(opts *ExtendedPortOpts) (*ports.Port, error) {
+	var createOpts ports.CreateOptsBuilder = opts.CreateOpts
+	if opts.PortSecurityEnabled != nil {
+		createOpts = portsecurity.PortCreateOptsExt{
+			CreateOptsBuilder:   createOpts,
+			PortSecurityEnabled: opts.PortSecurityEnabled,
+		}
+	}
+	return ports.Create(c.NetworkV2, createOpts).Extract()
+}
+
+// GetPort returns port details
+func (c *Client) GetPort(portID string) (*ports.Port, error) {
+	return ports.Get(c.NetworkV2, portID).Extract()
+}
+
+// FindPort finds port by its name and returns port ID
+func (c *Client) FindPort(name string) (string, error) {
+	page, err := ports.List(c.NetworkV2, ports.ListOpts{Name: name}).AllPages()
+	if err != nil {
+		return "", err
+	}
+	found, err := ports.ExtractPorts(page)
+	if err != nil {
+		return "", err
+	}
+	if len(found) == 0 {
+		return "", nil
+	}
+	if len(found) > 1 {
+		return "", fmt.Errorf("multiple ports found by name %s. Please provide port ID instead", name)
+	}
+	return found[0].ID, nil
+}
+
+// UpdatePort updates existing port
+func (c *Client) UpdatePort(portID string, opts ports.UpdateOptsBuilder) (*ports.Port, error) {
+	return ports.Update(c.NetworkV2, portID, opts).Extract()
+}
+
+// SetPortSecurity enables or disables port security of the port
+// Security groups are removed from the port when port security is disabled
+func (c *Client) SetPortSecurity(portID string, enabled bool) error {
+	updateOpts := ports.UpdateOpts{}
+	if !enabled {
+		updateOpts.SecurityGroups = &[]string{}
+	}
+	opts := portsecurity.PortUpdateOptsExt{
+		UpdateOptsBuilder:   updateOpts,
+		PortSecurityEnabled: &enabled,
+	}
+	return ports.Update(c.NetworkV2, portID, opts).Err
+}
+
+// AddAllowedAddressPairs adds allowed address pairs to the port keeping existing ones
+func (c *Client) AddAllowedAddressPairs(portID string, pairs ...ports.AddressPair) error {
+	port, err := c.GetPort(portID)
+	if err != nil {
+		return err
+	}
+	result := port.AllowedAddressPairs
+	for _, pair := range pairs {
+		exists := false
+		for _, existing := range result {
+			if existing.IPAddress == pair.IPAddress {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			result = append(result, pair)
+		}
+	}
+	_, err = c.UpdatePort(portID, ports.UpdateOpts{AllowedAddressPairs: &result})
+	return err
+}
+
+// RemoveAllowedAddressPairs removes allowed address pairs with given IP addresses from the port
+func (c *Client) RemoveAllowedAddressPairs(portID string, ipAddresses ...string) error {
+	port, err := c.GetPort(portID)
+	if err != nil {
+		return err
+	}
+	result := make([]ports.AddressPair, 0, len(port.AllowedAddressPairs))
+	for _, pair := range port.AllowedAddressPairs {
+		removed := false
+		for _, ip := range ipAddresses {
+			if pair.IPAddress == ip {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			result = append(result, pair)
+		}
+	}
+	_, err = c.UpdatePort(portID, ports.UpdateOpts{AllowedAddressPairs: &result})
+	return err
+}
+
+// DeletePort removes existing port
+func (c *Client) DeletePort(portID string) error {
+	return ports.Delete(c.NetworkV2, portID).Err
+}
+
+// AssociateFloatingIP binds floating IP to the port of the first instance NIC
+// Both Compute v2 and Neutron clients should be initialized
+func (c *Client) AssociateFloatingIP(floatingIP, instanceID string) error {
+	pages, err := attachinterfaces.List(c.ComputeV2, instanceID).AllPages()
+	if err != nil {
+		return err
+	}
+	interfaces, err := attachinterfaces.ExtractInterfaces(pages)
+	if err != nil {
+		return err
+	}
+	if len(interfaces) == 0 {
+		return fmt.Errorf("instance `%s` has no network interfaces", instanceID)
+	}
+	return c.BindFloatingIPToPort(floatingIP, interfaces[0].PortID)
+}
+
 // BindFloatingIPToPort binds floating IP to networking port
 func (c *Client) BindFloatingIPToPort(floatingIP, portID string) error {
-	page, err := floatingips.List(c.NetworkV2, floatingips.ListOpts{
-		FloatingIP: floatingIP,
-	}).AllPages()
+	ip, err := c.getExistingFloatingIP(floatingIP)
 	if err != nil {
 		return err
 	}
-	ids, err := floatingips.ExtractFloatingIPs(page)
+	opts := floatingips.UpdateOpts{PortID: &portID}
+	return floatingips.Update(c.NetworkV2, ip.ID, opts).Err
+}
+
+// DisassociateFloatingIP unbinds floating IP from any port
+func (c *Client) DisassociateFloatingIP(floatingIP string) error {
+	ip, err := c.getExistingFloatingIP(floatingIP)
 	if err != nil {
 		return err
 	}
-	if len(ids) == 0 {
-		return fmt.Errorf("failed to find existing floating IP `%s`", floatingIP)
+	opts := floatingips.UpdateOpts{PortID: nil}
+	return floatingips.Update(c.NetworkV2, ip.ID, opts).Err
+}
+
+// ReleaseFloatingIP releases floating IP
+func (c *Client) ReleaseFloatingIP(floatingIP string) error {
+	ip, err := c.getExistingFloatingIP(floatingIP)
+	if err != nil {
+		return err
 	}
-	opts := floatingips.UpdateOpts{PortID: &portID}
-	return floatingips.Update(c.NetworkV2, ids[0].ID, opts).Err
+	return floatingips.Delete(c.NetworkV2, ip.ID).Err
 }
 
 func (c *Client) CreateLBListener(opts *listeners.CreateOpts) (*listeners.Listener, error) {
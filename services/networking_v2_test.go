@@ -8,15 +8,19 @@ import (
 	"github.com/hashicorp/go-multierror"
 	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/layer3/floatingips"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/getlantern/deepcopy"
+
+	"github.com/opentelekomcloud-infra/crutch-house/utils"
 )
 
 func initClients(t *testing.T, client *Client) {
@@ -273,3 +277,66 @@ func TestClient_LoadBalancerLifecycle(t *testing.T) {
 		assert.NoError(t, client.DeleteLBMonitor(monitor.ID))
 	}()
 }
+
+func TestClient_FloatingIPLifecycle(t *testing.T) {
+	client := authClient(t)
+	require.NoError(t, client.InitNetworkV2())
+	require.NoError(t, client.InitCompute())
+
+	ip, err := client.AllocateFloatingIP("")
+	require.NoError(t, err)
+	address := ip.FloatingIP
+	defer func() { _ = client.ReleaseFloatingIP(address) }()
+
+	found, err := client.FindFloatingIPDetails(address)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, ip.ID, found.ID)
+
+	ips, err := client.ListFloatingIPs(floatingips.ListOpts{FloatingNetworkID: ip.FloatingNetworkID})
+	require.NoError(t, err)
+	assert.NotEmpty(t, ips)
+
+	assert.Error(t, client.AssociateFloatingIP(address, "not-existing-instance"))
+
+	require.NoError(t, client.ReleaseFloatingIP(address))
+	found, err = client.FindFloatingIPDetails(address)
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestClient_PortLifecycle(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitNetworkV2())
+
+	subnetID, cleanupNetwork := createTestNetwork(t, client)
+	defer cleanupNetwork()
+
+	portName := utils.RandomString(12, "port-")
+	port, err := client.CreatePort(&ExtendedPortOpts{
+		CreateOpts: &ports.CreateOpts{
+			Name:      portName,
+			NetworkID: subnetID,
+		},
+	})
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, client.DeletePort(port.ID)) }()
+
+	found, err := client.FindPort(portName)
+	require.NoError(t, err)
+	assert.Equalf(t, port.ID, found, invalidFind, "port")
+
+	vip := "192.168.0.200"
+	require.NoError(t, client.AddAllowedAddressPairs(port.ID, ports.AddressPair{IPAddress: vip}))
+	details, err := client.GetPort(port.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []ports.AddressPair{{IPAddress: vip, MACAddress: port.MACAddress}}, details.AllowedAddressPairs)
+
+	require.NoError(t, client.RemoveAllowedAddressPairs(port.ID, vip))
+	details, err = client.GetPort(port.ID)
+	require.NoError(t, err)
+	assert.Empty(t, details.AllowedAddressPairs)
+
+	assert.NoError(t, client.SetPortSecurity(port.ID, false))
+	assert.NoError(t, client.SetPortSecurity(port.ID, true))
+}
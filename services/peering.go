@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/peerings"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/routes"
+
+	"github.com/opentelekomcloud-infra/crutch-house/ipplan"
+)
+
+// VPC peering statuses
+const (
+	PeeringStatusPending  = "PENDING_ACCEPTANCE"
+	PeeringStatusActive   = "ACTIVE"
+	PeeringStatusRejected = "REJECTED"
+	PeeringStatusExpired  = "EXPIRED"
+)
+
+const peeringTimeout = 300
+
+// PeeringOpts contains optional VPC peering parameters
+type PeeringOpts struct {
+	PeerProjectID string // project of peer VPC, current project is used if empty
+	PeerCIDR      string // CIDR of peer VPC, required to validate peering with VPC of other project
+}
+
+// validatePeeringCIDRs checks that VPC CIDRs don't overlap, otherwise traffic can't be routed through peering
+func validatePeeringCIDRs(vpcCIDR, peerCIDR string) error {
+	overlaps, err := ipplan.Overlaps(vpcCIDR, peerCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid VPC CIDR: %s", err)
+	}
+	if overlaps {
+		return fmt.Errorf("VPC CIDR %s overlaps with peer VPC CIDR %s", vpcCIDR, peerCIDR)
+	}
+	return nil
+}
+
+// CreatePeering requests peering between VPC and peer VPC, `opts` can be nil
+// VPC CIDRs are validated before sending the request
+func (c *Client) CreatePeering(name, vpcID, peerVPCID string, opts *PeeringOpts) (*peerings.Peering, error) {
+	if opts == nil {
+		opts = &PeeringOpts{}
+	}
+	vpc, err := c.GetVPCDetails(vpcID)
+	if err != nil {
+		return nil, err
+	}
+	peerCIDR := opts.PeerCIDR
+	if peerCIDR == "" {
+		if opts.PeerProjectID != "" && opts.PeerProjectID != c.Provider.ProjectID {
+			return nil, fmt.Errorf("peer CIDR is required for peering with VPC of other project")
+		}
+		peerVPC, err := c.GetVPCDetails(peerVPCID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting peer VPC details: %s", err)
+		}
+		peerCIDR = peerVPC.CIDR
+	}
+	if err := validatePeeringCIDRs(vpc.CIDR, peerCIDR); err != nil {
+		return nil, err
+	}
+	return peerings.Create(c.NetworkV2, peerings.CreateOpts{
+		Name:           name,
+		RequestVpcInfo: peerings.VpcInfo{VpcId: vpcID},
+		AcceptVpcInfo:  peerings.VpcInfo{VpcId: peerVPCID, TenantId: opts.PeerProjectID},
+	}).Extract()
+}
+
+// GetPeering returns VPC peering details
+func (c *Client) GetPeering(peeringID string) (*peerings.Peering, error) {
+	return peerings.Get(c.NetworkV2, peeringID).Extract()
+}
+
+// WaitForPeeringStatus waits until VPC peering is in given status
+func (c *Client) WaitForPeeringStatus(peeringID, status string) error {
+	return golangsdk.WaitFor(peeringTimeout, func() (bool, error) {
+		peering, err := c.GetPeering(peeringID)
+		if err != nil {
+			return true, err
+		}
+		if peering.Status == status {
+			return true, nil
+		}
+		if peering.Status == PeeringStatusRejected || peering.Status == PeeringStatusExpired {
+			return true, fmt.Errorf("VPC peering `%s` is in %s state", peeringID, peering.Status)
+		}
+		return false, nil
+	})
+}
+
+// AcceptPeering accepts VPC peering request and waits for peering to become active
+// This should be called by the project owning peer VPC
+func (c *Client) AcceptPeering(peeringID string) error {
+	if _, err := peerings.Accept(c.NetworkV2, peeringID).ExtractResult(); err != nil {
+		return fmt.Errorf("error accepting VPC peering: %s", err)
+	}
+	return c.WaitForPeeringStatus(peeringID, PeeringStatusActive)
+}
+
+// DeletePeering removes all routes through VPC peering, deletes the peering and waits until it is gone
+func (c *Client) DeletePeering(peeringID string) error {
+	if err := c.DeletePeeringRoutes(peeringID); err != nil {
+		return err
+	}
+	if err := peerings.Delete(c.NetworkV2, peeringID).ExtractErr(); err != nil {
+		return fmt.Errorf("error deleting VPC peering: %s", err)
+	}
+	return golangsdk.WaitFor(peeringTimeout, func() (bool, error) {
+		_, err := c.GetPeering(peeringID)
+		if err == nil {
+			return false, nil
+		}
+		switch err.(type) {
+		case golangsdk.ErrDefault404:
+			return true, nil
+		default:
+			return true, err
+		}
+	})
+}
+
+// AddPeeringRoutes adds routes through VPC peering on both sides, so each VPC CIDR is routed to the other VPC
+// Both VPCs should belong to the current project, use AddPeeringRoute for each side otherwise
+func (c *Client) AddPeeringRoutes(peeringID string) ([]Route, error) {
+	peering, err := c.GetPeering(peeringID)
+	if err != nil {
+		return nil, err
+	}
+	if peering.Status != PeeringStatusActive {
+		return nil, fmt.Errorf("VPC peering `%s` is in %s state, %s expected", peeringID, peering.Status, PeeringStatusActive)
+	}
+	requestVPC, err := c.GetVPCDetails(peering.RequestVpcInfo.VpcId)
+	if err != nil {
+		return nil, err
+	}
+	if tenant := peering.AcceptVpcInfo.TenantId; tenant != "" && tenant != peering.RequestVpcInfo.TenantId {
+		return nil, fmt.Errorf("VPC peering `%s` connects VPCs of different projects, routes should be added on each side", peeringID)
+	}
+	acceptVPC, err := c.GetVPCDetails(peering.AcceptVpcInfo.VpcId)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePeeringCIDRs(requestVPC.CIDR, acceptVPC.CIDR); err != nil {
+		return nil, err
+	}
+	first, err := c.AddPeeringRoute(peeringID, requestVPC.ID, acceptVPC.CIDR)
+	if err != nil {
+		return nil, err
+	}
+	second, err := c.AddPeeringRoute(peeringID, acceptVPC.ID, requestVPC.CIDR)
+	if err != nil {
+		return nil, multierror.Append(err, routes.Delete(c.NetworkV2, first.ID).ExtractErr())
+	}
+	return []Route{*first, *second}, nil
+}
+
+// AddPeeringRoute adds route to destination CIDR through VPC peering into given VPC route table
+func (c *Client) AddPeeringRoute(peeringID, vpcID, destination string) (*Route, error) {
+	return c.AddRoute(vpcID, RouteTypePeering, destination, peeringID)
+}
+
+// DeletePeeringRoutes removes all routes through VPC peering
+func (c *Client) DeletePeeringRoutes(peeringID string) error {
+	routeList, err := c.listPeeringRoutes(routes.ListOpts{})
+	if err != nil {
+		return err
+	}
+	mErr := &multierror.Error{}
+	for _, route := range routeList {
+		if route.NextHop != peeringID {
+			continue
+		}
+		mErr = multierror.Append(mErr, routes.Delete(c.NetworkV2, route.RouteID).ExtractErr())
+	}
+	return mErr.ErrorOrNil()
+}
+
+// ConnectVPCs creates peering between two VPCs of the current project and adds routes on both sides
+func (c *Client) ConnectVPCs(name, vpcID, peerVPCID string) (*peerings.Peering, error) {
+	peering, err := c.CreatePeering(name, vpcID, peerVPCID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.WaitForPeeringStatus(peering.ID, PeeringStatusActive); err != nil {
+		return nil, multierror.Append(err, c.DeletePeering(peering.ID))
+	}
+	if _, err := c.AddPeeringRoutes(peering.ID); err != nil {
+		return nil, multierror.Append(err, c.DeletePeering(peering.ID))
+	}
+	return c.GetPeering(peering.ID)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePeeringCIDRs(t *testing.T) {
+	assert.NoError(t, validatePeeringCIDRs("192.168.0.0/20", "10.0.0.0/16"))
+	assert.Error(t, validatePeeringCIDRs("192.168.0.0/16", "192.168.16.0/20"))
+	assert.Error(t, validatePeeringCIDRs("192.168.0.0/16", "invalid"))
+}
+
+func createPeeringTestVPC(t *testing.T, client *Client, name, cidr string) string {
+	vpc, err := client.CreateVPCWithOpts(name, &VPCOpts{CIDR: cidr})
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForVPCStatus(vpc.ID, "OK"))
+	return vpc.ID
+}
+
+func TestClient_ConnectVPCs(t *testing.T) {
+	client := authClient(t)
+	initNetwork(t, client)
+	require.NoError(t, client.InitNetworkV2())
+
+	toolsID := createPeeringTestVPC(t, client, vpcName+"-tools", "10.10.0.0/16")
+	defer func() { assert.NoError(t, client.DeleteVPC(toolsID)) }()
+	workloadID := createPeeringTestVPC(t, client, vpcName+"-workload", "10.20.0.0/16")
+	defer func() { assert.NoError(t, client.DeleteVPC(workloadID)) }()
+	overlappingID := createPeeringTestVPC(t, client, vpcName+"-overlapping", "10.10.128.0/20")
+	defer func() { assert.NoError(t, client.DeleteVPC(overlappingID)) }()
+
+	_, err := client.CreatePeering("test-peering", toolsID, overlappingID, nil)
+	assert.Error(t, err)
+
+	peering, err := client.ConnectVPCs("test-peering", toolsID, workloadID)
+	require.NoError(t, err)
+	assert.Equal(t, PeeringStatusActive, peering.Status)
+
+	assert.NoError(t, client.DeletePeering(peering.ID))
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/subnets"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/vpcs"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/routes"
+)
+
+// RouteType is a kind of route next hop
+type RouteType string
+
+// Route next hop kinds
+const (
+	RouteTypePeering RouteType = "peering" // next hop is VPC peering ID
+	RouteTypeECS     RouteType = "ecs"     // next hop is ECS instance ID
+	RouteTypeVIP     RouteType = "vip"     // next hop is virtual IP address
+	RouteTypeNAT     RouteType = "nat"     // next hop is private IP address of NAT instance
+	RouteTypeIP      RouteType = "ip"      // next hop is IP address not owned by any VPC port
+)
+
+// sourceDestCheckDisabled is allowed address pair set on ports with disabled source/destination check
+const sourceDestCheckDisabled = "1.1.1.1/0"
+
+// Route is a VPC route table entry
+// ECS instance with disabled source/destination check is a NAT instance, routes through it are NAT routes
+type Route struct {
+	ID          string // set for peering routes only
+	VPCID       string
+	Type        RouteType
+	Destination string
+	NextHop     string // peering ID, instance ID or IP address depending on route type
+	NextHopIP   string // empty for peering routes
+}
+
+// vpcRoutesOpts replaces VPC static routes
+type vpcRoutesOpts struct {
+	Name   string       `json:"name"`
+	Routes []vpcs.Route `json:"routes"`
+}
+
+func (opts vpcRoutesOpts) ToVpcUpdateMap() (map[string]interface{}, error) {
+	return golangsdk.BuildRequestBody(opts, "vpc")
+}
+
+// GetVPCRoutes returns all routes of VPC route table with typed next hops
+// Unlike `Routes` of GetVPCDetails result, it includes peering routes
+func (c *Client) GetVPCRoutes(vpcID string) ([]Route, error) {
+	vpc, err := c.GetVPCDetails(vpcID)
+	if err != nil {
+		return nil, err
+	}
+	return c.vpcRoutes(vpc)
+}
+
+func (c *Client) vpcRoutes(vpc *vpcs.Vpc) ([]Route, error) {
+	peeringRoutes, err := c.listPeeringRoutes(routes.ListOpts{VPC_ID: vpc.ID})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Route, 0, len(peeringRoutes)+len(vpc.Routes))
+	for _, route := range peeringRoutes {
+		result = append(result, Route{
+			ID:          route.RouteID,
+			VPCID:       route.VPC_ID,
+			Type:        RouteTypePeering,
+			Destination: route.Destination,
+			NextHop:     route.NextHop,
+		})
+	}
+	if len(vpc.Routes) == 0 {
+		return result, nil
+	}
+	portsByIP, err := c.vpcPortsByIP(vpc.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range vpc.Routes {
+		result = append(result, classifyStaticRoute(vpc.ID, route, portsByIP))
+	}
+	return result, nil
+}
+
+func (c *Client) listPeeringRoutes(opts routes.ListOpts) ([]routes.Route, error) {
+	opts.Type = string(RouteTypePeering)
+	pages, err := routes.List(c.NetworkV2, opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	return routes.ExtractRoutes(pages)
+}
+
+// vpcPortsByIP returns ports of all VPC subnets mapped by fixed IP address
+func (c *Client) vpcPortsByIP(vpcID string) (map[string]ports.Port, error) {
+	subnetList, err := subnets.List(c.VPC, subnets.ListOpts{VpcID: vpcID})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]ports.Port)
+	for _, subnet := range subnetList {
+		if subnet.VpcID != vpcID {
+			continue
+		}
+		page, err := ports.List(c.NetworkV2, ports.ListOpts{NetworkID: subnet.ID}).AllPages()
+		if err != nil {
+			return nil, err
+		}
+		portList, err := ports.ExtractPorts(page)
+		if err != nil {
+			return nil, err
+		}
+		for _, port := range portList {
+			for _, ip := range port.FixedIPs {
+				result[ip.IPAddress] = port
+			}
+		}
+	}
+	return result, nil
+}
+
+func isNATPort(port ports.Port) bool {
+	for _, pair := range port.AllowedAddressPairs {
+		if pair.IPAddress == sourceDestCheckDisabled {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyStaticRoute detects static route type by the port owning next hop IP address
+func classifyStaticRoute(vpcID string, route vpcs.Route, portsByIP map[string]ports.Port) Route {
+	result := Route{
+		VPCID:       vpcID,
+		Type:        RouteTypeIP,
+		Destination: route.DestinationCIDR,
+		NextHop:     route.NextHop,
+		NextHopIP:   route.NextHop,
+	}
+	port, ok := portsByIP[route.NextHop]
+	switch {
+	case !ok:
+	case port.DeviceOwner == vipDeviceOwner:
+		result.Type = RouteTypeVIP
+	case strings.HasPrefix(port.DeviceOwner, "compute:") && isNATPort(port):
+		result.Type = RouteTypeNAT
+	case strings.HasPrefix(port.DeviceOwner, "compute:"):
+		result.Type = RouteTypeECS
+		result.NextHop = port.DeviceID
+	}
+	return result
+}
+
+// instanceVPCAddress returns private IP address of the instance in given VPC
+func (c *Client) instanceVPCAddress(vpcID, instanceID string) (string, error) {
+	subnetList, err := subnets.List(c.VPC, subnets.ListOpts{VpcID: vpcID})
+	if err != nil {
+		return "", err
+	}
+	for _, subnet := range subnetList {
+		if subnet.VpcID != vpcID {
+			continue
+		}
+		port, err := c.FindInstancePort(instanceID, subnet.ID)
+		if err != nil {
+			return "", err
+		}
+		if port != nil && len(port.FixedIPs) > 0 {
+			return port.FixedIPs[0].IPAddress, nil
+		}
+	}
+	return "", fmt.Errorf("instance `%s` has no address in VPC `%s`", instanceID, vpcID)
+}
+
+// AddRoute adds route to destination CIDR into VPC route table
+// `nextHop` is VPC peering ID, ECS instance ID or IP address depending on route type
+// Next hop of static route should match route type the same way as routes are listed by GetVPCRoutes
+func (c *Client) AddRoute(vpcID string, routeType RouteType, destination, nextHop string) (*Route, error) {
+	switch routeType {
+	case RouteTypePeering:
+		route, err := routes.Create(c.NetworkV2, routes.CreateOpts{
+			Type:        string(RouteTypePeering),
+			NextHop:     nextHop,
+			Destination: destination,
+			VPC_ID:      vpcID,
+		}).Extract()
+		if err != nil {
+			return nil, fmt.Errorf("error adding route to %s in VPC `%s`: %s", destination, vpcID, err)
+		}
+		return &Route{
+			ID:          route.RouteID,
+			VPCID:       vpcID,
+			Type:        routeType,
+			Destination: route.Destination,
+			NextHop:     route.NextHop,
+		}, nil
+	case RouteTypeECS, RouteTypeVIP, RouteTypeNAT, RouteTypeIP:
+	default:
+		return nil, fmt.Errorf("unknown route type `%s`", routeType)
+	}
+
+	nextHopIP := nextHop
+	if routeType == RouteTypeECS {
+		address, err := c.instanceVPCAddress(vpcID, nextHop)
+		if err != nil {
+			return nil, err
+		}
+		nextHopIP = address
+	}
+	vpc, err := c.GetVPCDetails(vpcID)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range vpc.Routes {
+		if route.DestinationCIDR == destination {
+			return nil, fmt.Errorf("VPC `%s` already has route to %s via %s", vpcID, destination, route.NextHop)
+		}
+	}
+	portsByIP, err := c.vpcPortsByIP(vpcID)
+	if err != nil {
+		return nil, err
+	}
+	staticRoute := vpcs.Route{DestinationCIDR: destination, NextHop: nextHopIP}
+	result := classifyStaticRoute(vpcID, staticRoute, portsByIP)
+	if result.Type != routeType {
+		return nil, fmt.Errorf("next hop %s of route to %s is %s, not %s", nextHopIP, destination, result.Type, routeType)
+	}
+	newRoutes := make([]vpcs.Route, len(vpc.Routes), len(vpc.Routes)+1)
+	copy(newRoutes, vpc.Routes)
+	newRoutes = append(newRoutes, staticRoute)
+	if err := c.updateStaticRoutes(vpc, newRoutes); err != nil {
+		return nil, fmt.Errorf("error adding route to %s in VPC `%s`: %s", destination, vpcID, err)
+	}
+	return &result, nil
+}
+
+func (c *Client) updateStaticRoutes(vpc *vpcs.Vpc, newRoutes []vpcs.Route) error {
+	if newRoutes == nil {
+		newRoutes = []vpcs.Route{}
+	}
+	opts := vpcRoutesOpts{Name: vpc.Name, Routes: newRoutes}
+	return vpcs.Update(c.VPC, vpc.ID, opts).Err
+}
+
+// DeleteRoute removes route to destination CIDR from VPC route table
+func (c *Client) DeleteRoute(vpcID, destination string) error {
+	peeringRoutes, err := c.listPeeringRoutes(routes.ListOpts{VPC_ID: vpcID, Destination: destination})
+	if err != nil {
+		return err
+	}
+	for _, route := range peeringRoutes {
+		if route.VPC_ID == vpcID && route.Destination == destination {
+			return routes.Delete(c.NetworkV2, route.RouteID).ExtractErr()
+		}
+	}
+
+	vpc, err := c.GetVPCDetails(vpcID)
+	if err != nil {
+		return err
+	}
+	var newRoutes []vpcs.Route
+	for _, route := range vpc.Routes {
+		if route.DestinationCIDR != destination {
+			newRoutes = append(newRoutes, route)
+		}
+	}
+	if len(newRoutes) == len(vpc.Routes) {
+		return fmt.Errorf("VPC `%s` has no route to %s", vpcID, destination)
+	}
+	return c.updateStaticRoutes(vpc, newRoutes)
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/vpcs"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyStaticRoute(t *testing.T) {
+	portsByIP := map[string]ports.Port{
+		"192.168.0.10": {DeviceOwner: "compute:eu-de-01", DeviceID: "instance-id"},
+		"192.168.0.20": {DeviceOwner: vipDeviceOwner},
+		"192.168.0.30": {
+			DeviceOwner:         "compute:eu-de-02",
+			DeviceID:            "nat-instance-id",
+			AllowedAddressPairs: []ports.AddressPair{{IPAddress: sourceDestCheckDisabled}},
+		},
+		"192.168.0.40": {DeviceOwner: "network:dhcp"},
+	}
+
+	cases := []struct {
+		name      string
+		nextHop   string
+		routeType RouteType
+		expected  string
+	}{
+		{"ecs", "192.168.0.10", RouteTypeECS, "instance-id"},
+		{"vip", "192.168.0.20", RouteTypeVIP, "192.168.0.20"},
+		{"nat", "192.168.0.30", RouteTypeNAT, "192.168.0.30"},
+		{"other port", "192.168.0.40", RouteTypeIP, "192.168.0.40"},
+		{"no port", "192.168.0.50", RouteTypeIP, "192.168.0.50"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			route := classifyStaticRoute("vpc-id", vpcs.Route{DestinationCIDR: "10.0.0.0/8", NextHop: c.nextHop}, portsByIP)
+			assert.Equal(t, c.routeType, route.Type)
+			assert.Equal(t, c.expected, route.NextHop)
+			assert.Equal(t, c.nextHop, route.NextHopIP)
+			assert.Equal(t, "10.0.0.0/8", route.Destination)
+			assert.Equal(t, "vpc-id", route.VPCID)
+		})
+	}
+}
+
+func TestClient_RouteLifecycle(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitNetworkV2())
+
+	subnetID, cleanupNetwork := createTestNetwork(t, client)
+	defer cleanupNetwork()
+
+	vip, err := client.CreateVirtualIP(subnetID, nil, &VirtualIPOpts{Name: "test-route-vip"})
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, client.DeleteVirtualIP(vip)) }()
+
+	vpcID, err := client.FindVPC(vpcName)
+	require.NoError(t, err)
+
+	_, err = client.AddRoute(vpcID, "unknown", "0.0.0.0/0", vip.IPAddress)
+	assert.Error(t, err)
+
+	route, err := client.AddRoute(vpcID, RouteTypeVIP, "0.0.0.0/0", vip.IPAddress)
+	require.NoError(t, err)
+	assert.Equal(t, vip.IPAddress, route.NextHopIP)
+
+	_, err = client.AddRoute(vpcID, RouteTypeNAT, "0.0.0.0/0", vip.IPAddress)
+	assert.Error(t, err)
+
+	routeList, err := client.GetVPCRoutes(vpcID)
+	require.NoError(t, err)
+	require.Len(t, routeList, 1)
+	assert.Equal(t, RouteTypeVIP, routeList[0].Type)
+	assert.Equal(t, "0.0.0.0/0", routeList[0].Destination)
+
+	require.NoError(t, client.DeleteRoute(vpcID, "0.0.0.0/0"))
+	routeList, err = client.GetVPCRoutes(vpcID)
+	require.NoError(t, err)
+	assert.Empty(t, routeList)
+	assert.Error(t, client.DeleteRoute(vpcID, "0.0.0.0/0"))
+}
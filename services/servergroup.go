@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/servergroups"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+)
+
+// Server group policies
+const (
+	ServerGroupAffinity         = "affinity"
+	ServerGroupAntiAffinity     = "anti-affinity"
+	ServerGroupSoftAffinity     = "soft-affinity"
+	ServerGroupSoftAntiAffinity = "soft-anti-affinity"
+)
+
+// softPoliciesMicroversion is the minimal compute API microversion supporting soft policies
+const softPoliciesMicroversion = "2.15"
+
+var validServerGroupPolicies = []string{
+	ServerGroupAffinity, ServerGroupAntiAffinity, ServerGroupSoftAffinity, ServerGroupSoftAntiAffinity,
+}
+
+// CreateServerGroupWithPolicy creates server group with one of ServerGroup policies
+func (c *Client) CreateServerGroupWithPolicy(name string, policy string) (*servergroups.ServerGroup, error) {
+	valid := false
+	for _, p := range validServerGroupPolicies {
+		if p == policy {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid server group policy `%s`, expected one of: %s",
+			policy, strings.Join(validServerGroupPolicies, ", "))
+	}
+	opts := &servergroups.CreateOpts{
+		Name:     name,
+		Policies: []string{policy},
+	}
+	if policy != ServerGroupSoftAffinity && policy != ServerGroupSoftAntiAffinity {
+		return c.CreateServerGroup(opts)
+	}
+	computeClient := *c.ComputeV2
+	computeClient.Microversion = softPoliciesMicroversion
+	return servergroups.Create(&computeClient, opts).Extract()
+}
+
+// GetServerGroup returns server group details by ID
+func (c *Client) GetServerGroup(groupID string) (*servergroups.ServerGroup, error) {
+	return servergroups.Get(c.ComputeV2, groupID).Extract()
+}
+
+// ListServerGroupMembers returns details of all instances in server group
+func (c *Client) ListServerGroupMembers(groupID string) ([]*servers.Server, error) {
+	group, err := c.GetServerGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]*servers.Server, len(group.Members))
+	for i, instanceID := range group.Members {
+		instance, err := c.GetInstanceStatus(instanceID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting server group member `%s`: %s", instanceID, err)
+		}
+		members[i] = instance
+	}
+	return members, nil
+}
+
+// checkPlacement validates that instance hosts, mapped by instance ID, satisfy server group policy
+func checkPlacement(policy string, hosts map[string]string) error {
+	byHost := make(map[string][]string)
+	for instanceID, hostID := range hosts {
+		if hostID == "" {
+			return fmt.Errorf("instance `%s` is not placed on any host", instanceID)
+		}
+		byHost[hostID] = append(byHost[hostID], instanceID)
+	}
+	switch policy {
+	case ServerGroupAffinity, ServerGroupSoftAffinity:
+		if len(byHost) > 1 {
+			return fmt.Errorf("instances are placed on %d different hosts, single host expected", len(byHost))
+		}
+	case ServerGroupAntiAffinity, ServerGroupSoftAntiAffinity:
+		var shared []string
+		for _, instanceIDs := range byHost {
+			if len(instanceIDs) > 1 {
+				sort.Strings(instanceIDs)
+				shared = append(shared, strings.Join(instanceIDs, ", "))
+			}
+		}
+		if len(shared) > 0 {
+			sort.Strings(shared)
+			return fmt.Errorf("instances share the same host: [%s]", strings.Join(shared, "], ["))
+		}
+	default:
+		return fmt.Errorf("unknown server group policy `%s`", policy)
+	}
+	return nil
+}
+
+// ValidateDistinctHosts checks that all given instances are running on different hosts
+func (c *Client) ValidateDistinctHosts(instanceIDs ...string) error {
+	hosts := make(map[string]string, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		instance, err := c.GetInstanceStatus(instanceID)
+		if err != nil {
+			return err
+		}
+		hosts[instanceID] = instance.HostID
+	}
+	return checkPlacement(ServerGroupAntiAffinity, hosts)
+}
+
+// ValidateServerGroupPlacement checks that host placement of server group members satisfies group policy
+func (c *Client) ValidateServerGroupPlacement(groupID string) error {
+	group, err := c.GetServerGroup(groupID)
+	if err != nil {
+		return err
+	}
+	if len(group.Policies) == 0 {
+		return fmt.Errorf("server group `%s` has no policy", groupID)
+	}
+	members, err := c.ListServerGroupMembers(groupID)
+	if err != nil {
+		return err
+	}
+	hosts := make(map[string]string, len(members))
+	for _, member := range members {
+		hosts[member.ID] = member.HostID
+	}
+	return checkPlacement(group.Policies[0], hosts)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPlacement(t *testing.T) {
+	distinct := map[string]string{"a": "host-1", "b": "host-2"}
+	shared := map[string]string{"a": "host-1", "b": "host-1", "c": "host-2"}
+
+	assert.NoError(t, checkPlacement(ServerGroupAntiAffinity, distinct))
+	assert.NoError(t, checkPlacement(ServerGroupSoftAntiAffinity, distinct))
+	assert.EqualError(t, checkPlacement(ServerGroupAntiAffinity, shared), "instances share the same host: [a, b]")
+
+	assert.NoError(t, checkPlacement(ServerGroupAffinity, map[string]string{"a": "host-1", "b": "host-1"}))
+	assert.Error(t, checkPlacement(ServerGroupSoftAffinity, distinct))
+
+	assert.Error(t, checkPlacement("unknown", distinct))
+
+	unscheduled := map[string]string{"a": "", "b": ""}
+	assert.EqualError(t, checkPlacement(ServerGroupAffinity, map[string]string{"a": ""}), "instance `a` is not placed on any host")
+	assert.Error(t, checkPlacement(ServerGroupAntiAffinity, unscheduled))
+}
+
+func TestClient_ServerGroupPlacement(t *testing.T) {
+	client := computeClient(t)
+
+	_, err := client.CreateServerGroupWithPolicy("test-group", "invalid")
+	assert.Error(t, err)
+
+	grp, err := client.CreateServerGroupWithPolicy("test-group", ServerGroupAntiAffinity)
+	require.NoError(t, err)
+	defer deleteServerGroup(client, grp.ID)
+
+	subnetID, cleanupNetwork := createTestNetwork(t, client)
+	defer cleanupNetwork()
+
+	opts := testInstanceOpts(t, client, subnetID)
+	defer func() { _ = client.DeleteKeyPair(kpName) }()
+	opts.ServerGroupID = grp.ID
+
+	results, err := client.CreateInstances(opts, 2, serverName+"-%d", &BulkCreateOpts{Rollback: true})
+	require.NoError(t, err)
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Instance.ID)
+	}
+	defer func() { assert.NoError(t, client.DeleteInstances(ids)) }()
+
+	members, err := client.ListServerGroupMembers(grp.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	assert.NoError(t, client.ValidateServerGroupPlacement(grp.ID))
+	assert.NoError(t, client.ValidateDistinctHosts(ids...))
+}
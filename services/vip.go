@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/eips"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+)
+
+const vipDeviceOwner = "neutron:VIP_PORT"
+
+// VirtualIPOpts contains optional virtual IP parameters
+type VirtualIPOpts struct {
+	Name      string
+	IPAddress string         // allocated automatically if empty
+	EIP       *ElasticIPOpts // EIP is created and bound to the virtual IP if set
+}
+
+// VirtualIP is a virtual IP shared by several instances
+type VirtualIP struct {
+	PortID        string
+	IPAddress     string
+	InstancePorts []string       // ports of instances virtual IP is bound to
+	PublicIP      *eips.PublicIp // nil if there is no EIP bound
+}
+
+// CreateVirtualIP creates virtual IP in VPC subnet and binds it to all given instances
+// VPC subnet ID should be used as `subnetID`, `opts` can be nil
+func (c *Client) CreateVirtualIP(subnetID string, instanceIDs []string, opts *VirtualIPOpts) (*VirtualIP, error) {
+	if opts == nil {
+		opts = &VirtualIPOpts{}
+	}
+	createOpts := &ports.CreateOpts{
+		Name:        opts.Name,
+		NetworkID:   subnetID,
+		DeviceOwner: vipDeviceOwner,
+	}
+	if opts.IPAddress != "" {
+		subnet, err := c.GetSubnetStatus(subnetID)
+		if err != nil {
+			return nil, err
+		}
+		createOpts.FixedIPs = []ports.IP{{SubnetID: subnet.SubnetID, IPAddress: opts.IPAddress}}
+	}
+	port, err := c.CreatePort(&ExtendedPortOpts{CreateOpts: createOpts})
+	if err != nil {
+		return nil, fmt.Errorf("error creating virtual IP port: %s", err)
+	}
+	if len(port.FixedIPs) == 0 {
+		return nil, multierror.Append(
+			fmt.Errorf("virtual IP port `%s` has no IP address", port.ID),
+			c.DeletePort(port.ID),
+		)
+	}
+	vip := &VirtualIP{
+		PortID:    port.ID,
+		IPAddress: port.FixedIPs[0].IPAddress,
+	}
+
+	if err := c.bindVirtualIP(vip, subnetID, instanceIDs, opts.EIP); err != nil {
+		return nil, multierror.Append(err, c.DeleteVirtualIP(vip))
+	}
+	return vip, nil
+}
+
+func (c *Client) bindVirtualIP(vip *VirtualIP, subnetID string, instanceIDs []string, eipOpts *ElasticIPOpts) error {
+	for _, instanceID := range instanceIDs {
+		port, err := c.FindInstancePort(instanceID, subnetID)
+		if err != nil {
+			return err
+		}
+		if port == nil {
+			return fmt.Errorf("instance `%s` has no port in subnet `%s`", instanceID, subnetID)
+		}
+		if err := c.AddAllowedAddressPairs(port.ID, ports.AddressPair{IPAddress: vip.IPAddress}); err != nil {
+			return fmt.Errorf("error binding virtual IP to instance `%s`: %s", instanceID, err)
+		}
+		vip.InstancePorts = append(vip.InstancePorts, port.ID)
+	}
+
+	if eipOpts == nil {
+		return nil
+	}
+	eip, err := c.CreateEIP(eipOpts)
+	if err != nil {
+		return fmt.Errorf("error creating EIP for virtual IP: %s", err)
+	}
+	vip.PublicIP = eip
+	if err := c.WaitForEIPActive(eip.ID); err != nil {
+		return err
+	}
+	return c.BindEIP(eip.ID, vip.PortID)
+}
+
+// DeleteVirtualIP releases virtual IP EIP, unbinds virtual IP from instances and removes it
+func (c *Client) DeleteVirtualIP(vip *VirtualIP) error {
+	mErr := &multierror.Error{}
+	if vip.PublicIP != nil {
+		mErr = multierror.Append(mErr, c.ReleaseEIP(vip.PublicIP.ID))
+	}
+	for _, portID := range vip.InstancePorts {
+		mErr = multierror.Append(mErr, c.RemoveAllowedAddressPairs(portID, vip.IPAddress))
+	}
+	mErr = multierror.Append(mErr, c.DeletePort(vip.PortID))
+	return mErr.ErrorOrNil()
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_VirtualIPLifecycle(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitNetworkV2())
+
+	subnetID, cleanupNetwork := createTestNetwork(t, client)
+	defer cleanupNetwork()
+
+	opts := testInstanceOpts(t, client, subnetID)
+	defer func() { _ = client.DeleteKeyPair(kpName) }()
+
+	results, err := client.CreateInstances(opts, 2, serverName+"-%d", &BulkCreateOpts{Rollback: true})
+	require.NoError(t, err)
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Instance.ID)
+	}
+	defer func() { assert.NoError(t, client.DeleteInstances(ids)) }()
+
+	vip, err := client.CreateVirtualIP(subnetID, ids, &VirtualIPOpts{
+		Name: "test-vip",
+		EIP:  eipOptions,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, vip.IPAddress)
+	assert.Len(t, vip.InstancePorts, 2)
+	require.NotNil(t, vip.PublicIP)
+
+	for _, portID := range vip.InstancePorts {
+		port, err := client.GetPort(portID)
+		require.NoError(t, err)
+		require.Len(t, port.AllowedAddressPairs, 1)
+		assert.Equal(t, vip.IPAddress, port.AllowedAddressPairs[0].IPAddress)
+	}
+
+	assert.NoError(t, client.DeleteVirtualIP(vip))
+}
@@ -2,22 +2,22 @@ package services
 
 import (
 	"fmt"
+	"net"
 
+	"github.com/apparentlymart/go-cidr/cidr"
 	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
-	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/eips"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/subnets"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/vpcs"
 
+	"github.com/opentelekomcloud-infra/crutch-house/ipplan"
 	"github.com/opentelekomcloud-infra/crutch-house/utils"
 )
 
 const (
-	vpcCIDR        = "192.168.0.0/20"
-	subnetCIDR     = "192.168.0.0/24"
-	primaryDNS     = "100.125.4.25"
-	secondaryDNS   = "8.8.8.8"
-	defaultGateway = "192.168.0.1"
-	bandwidthName  = "default-bandwidth"
+	vpcCIDR             = "192.168.0.0/20"
+	defaultSubnetPrefix = 24
+	primaryDNS          = "100.125.4.25"
+	secondaryDNS        = "8.8.8.8"
 )
 
 var defaultDNS = []string{primaryDNS, secondaryDNS}
@@ -35,11 +35,28 @@ func (c *Client) InitVPC() error {
 	return nil
 }
 
+// VPCOpts contains optional VPC parameters
+type VPCOpts struct {
+	CIDR string // 192.168.0.0/20 by default
+}
+
 // CreateVPC creates new VPC by d.VpcName
 func (c *Client) CreateVPC(vpcName string) (*vpcs.Vpc, error) {
+	return c.CreateVPCWithOpts(vpcName, nil)
+}
+
+// CreateVPCWithOpts creates new VPC with given parameters, `opts` can be nil
+func (c *Client) CreateVPCWithOpts(vpcName string, opts *VPCOpts) (*vpcs.Vpc, error) {
+	cidrBlock := vpcCIDR
+	if opts != nil && opts.CIDR != "" {
+		cidrBlock = opts.CIDR
+	}
+	if _, _, err := net.ParseCIDR(cidrBlock); err != nil {
+		return nil, fmt.Errorf("invalid VPC CIDR: %s", err)
+	}
 	return vpcs.Create(c.VPC, vpcs.CreateOpts{
 		Name: vpcName,
-		CIDR: vpcCIDR,
+		CIDR: cidrBlock,
 	}).Extract()
 }
 
@@ -83,25 +100,220 @@ func (c *Client) WaitForVPCStatus(vpcID, status string) error {
 	}, maxAttempts, waitInterval)
 }
 
+// sameCIDR checks if both CIDRs describe the same network
+func sameCIDR(first, second string) (bool, error) {
+	_, firstNet, err := net.ParseCIDR(first)
+	if err != nil {
+		return false, err
+	}
+	_, secondNet, err := net.ParseCIDR(second)
+	if err != nil {
+		return false, err
+	}
+	return firstNet.String() == secondNet.String(), nil
+}
+
+// EnsureVPC returns VPC with given name creating it if there is no such VPC, `opts` can be nil
+// Returned flag is true if VPC was created, CIDR of existing VPC should match the desired one if it's set
+// VPC is returned together with the error if it doesn't become ready
+func (c *Client) EnsureVPC(vpcName string, opts *VPCOpts) (*vpcs.Vpc, bool, error) {
+	vpcID, err := c.FindVPC(vpcName)
+	if err != nil {
+		return nil, false, err
+	}
+	var vpc *vpcs.Vpc
+	created := vpcID == ""
+	if created {
+		vpc, err = c.CreateVPCWithOpts(vpcName, opts)
+		if err != nil {
+			return nil, false, fmt.Errorf("error creating VPC %s: %s", vpcName, err)
+		}
+	} else {
+		vpc, err = c.GetVPCDetails(vpcID)
+		if err != nil {
+			return nil, false, err
+		}
+		if opts != nil && opts.CIDR != "" {
+			same, err := sameCIDR(vpc.CIDR, opts.CIDR)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid VPC CIDR: %s", err)
+			}
+			if !same {
+				return nil, false, fmt.Errorf("existing VPC %s has CIDR %s, %s expected", vpcName, vpc.CIDR, opts.CIDR)
+			}
+		}
+	}
+	if err := c.WaitForVPCStatus(vpc.ID, "OK"); err != nil {
+		return vpc, created, err
+	}
+	ready, err := c.GetVPCDetails(vpc.ID)
+	if err != nil {
+		return vpc, created, err
+	}
+	return ready, created, nil
+}
+
 // DeleteVPC removes existing VPC
 func (c *Client) DeleteVPC(vpcID string) error {
 	return vpcs.Delete(c.VPC, vpcID).Err
 }
 
+// waitForVPCDeleted waits until VPC is gone
+func (c *Client) waitForVPCDeleted(vpcID string) error {
+	return utils.WaitForSpecificOrError(func() (bool, error) {
+		_, err := c.GetVPCDetails(vpcID)
+		if err == nil {
+			return false, nil
+		}
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return true, nil
+		}
+		return true, err
+	}, maxAttempts, waitInterval)
+}
+
+// SubnetOpts contains optional subnet parameters
+type SubnetOpts struct {
+	CIDR             string   // next free block inside VPC is allocated if empty
+	PrefixLength     int      // prefix length of allocated block, 24 by default
+	GatewayIP        string   // first host of the subnet by default
+	DNSList          []string // OTC and Google DNS by default
+	DisableDHCP      bool
+	AvailabilityZone string
+}
+
 // CreateSubnet creates new Subnet and set Driver.SubnetID
 func (c *Client) CreateSubnet(vpcID string, subnetName string) (*subnets.Subnet, error) {
-	iTrue := true
+	return c.CreateSubnetWithOpts(vpcID, subnetName, nil)
+}
+
+// CreateSubnetWithOpts creates new subnet with given parameters, `opts` can be nil
+func (c *Client) CreateSubnetWithOpts(vpcID string, subnetName string, opts *SubnetOpts) (*subnets.Subnet, error) {
+	if opts == nil {
+		opts = &SubnetOpts{}
+	}
+	subnetBlock := opts.CIDR
+	if subnetBlock == "" {
+		allocated, err := c.AllocateSubnetCIDR(vpcID, opts.PrefixLength)
+		if err != nil {
+			return nil, err
+		}
+		subnetBlock = allocated
+	}
+	_, network, err := net.ParseCIDR(subnetBlock)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet CIDR: %s", err)
+	}
+	gateway := opts.GatewayIP
+	if gateway == "" {
+		gatewayIP, err := cidr.Host(network, 1)
+		if err != nil {
+			return nil, err
+		}
+		gateway = gatewayIP.String()
+	}
+	dnsList := opts.DNSList
+	if len(dnsList) == 0 {
+		dnsList = defaultDNS
+	}
+	enableDHCP := !opts.DisableDHCP
 	return subnets.Create(c.VPC, subnets.CreateOpts{
-		VpcID:      vpcID,
-		Name:       subnetName,
-		CIDR:       subnetCIDR,
-		DNSList:    defaultDNS,
-		GatewayIP:  defaultGateway,
-		EnableDHCP: &iTrue,
+		VpcID:            vpcID,
+		Name:             subnetName,
+		CIDR:             network.String(),
+		DNSList:          dnsList,
+		GatewayIP:        gateway,
+		EnableDHCP:       &enableDHCP,
+		AvailabilityZone: opts.AvailabilityZone,
 	},
 	).Extract()
 }
 
+// EnsureSubnet returns subnet with given name in VPC creating it if there is no such subnet, `opts` can be nil
+// Returned flag is true if subnet was created, CIDR or prefix length of existing subnet should match the desired one
+// Subnet is returned together with the error if it doesn't become ready
+func (c *Client) EnsureSubnet(vpcID string, subnetName string, opts *SubnetOpts) (*subnets.Subnet, bool, error) {
+	if opts == nil {
+		opts = &SubnetOpts{}
+	}
+	subnetID, err := c.FindSubnet(vpcID, subnetName)
+	if err != nil {
+		return nil, false, err
+	}
+	var subnet *subnets.Subnet
+	created := subnetID == ""
+	if created {
+		subnet, err = c.CreateSubnetWithOpts(vpcID, subnetName, opts)
+		if err != nil {
+			return nil, false, fmt.Errorf("error creating subnet %s: %s", subnetName, err)
+		}
+	} else {
+		subnet, err = c.GetSubnetStatus(subnetID)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := validateSubnetCIDR(subnet.CIDR, opts); err != nil {
+			return nil, false, fmt.Errorf("existing subnet %s: %s", subnetName, err)
+		}
+	}
+	if err := c.WaitForSubnetStatus(subnet.ID, "ACTIVE"); err != nil {
+		return subnet, created, err
+	}
+	ready, err := c.GetSubnetStatus(subnet.ID)
+	if err != nil {
+		return subnet, created, err
+	}
+	return ready, created, nil
+}
+
+// validateSubnetCIDR checks if subnet CIDR matches CIDR or prefix length from options
+func validateSubnetCIDR(subnetCIDR string, opts *SubnetOpts) error {
+	if opts.CIDR != "" {
+		same, err := sameCIDR(subnetCIDR, opts.CIDR)
+		if err != nil {
+			return err
+		}
+		if !same {
+			return fmt.Errorf("CIDR is %s, %s expected", subnetCIDR, opts.CIDR)
+		}
+		return nil
+	}
+	if opts.PrefixLength == 0 {
+		return nil
+	}
+	_, network, err := net.ParseCIDR(subnetCIDR)
+	if err != nil {
+		return err
+	}
+	if ones, _ := network.Mask.Size(); ones != opts.PrefixLength {
+		return fmt.Errorf("CIDR is %s, prefix length %d expected", subnetCIDR, opts.PrefixLength)
+	}
+	return nil
+}
+
+// AllocateSubnetCIDR returns the first block of given prefix length inside VPC CIDR
+// not overlapping with existing VPC subnets
+func (c *Client) AllocateSubnetCIDR(vpcID string, prefixLength int) (string, error) {
+	if prefixLength == 0 {
+		prefixLength = defaultSubnetPrefix
+	}
+	vpc, err := c.GetVPCDetails(vpcID)
+	if err != nil {
+		return "", err
+	}
+	subnetList, err := subnets.List(c.VPC, subnets.ListOpts{VpcID: vpcID})
+	if err != nil {
+		return "", err
+	}
+	used := make([]string, 0, len(subnetList))
+	for _, subnet := range subnetList {
+		if subnet.VpcID == vpcID {
+			used = append(used, subnet.CIDR)
+		}
+	}
+	return ipplan.NextFreeBlock(vpc.CIDR, used, prefixLength)
+}
+
 // FindSubnet find subnet by name in given VPC and return ID
 func (c *Client) FindSubnet(vpcID string, subnetName string) (string, error) {
 	subnetList, err := subnets.List(c.VPC, subnets.ListOpts{
@@ -148,57 +360,16 @@ func (c *Client) DeleteSubnet(vpcID string, subnetID string) error {
 	return subnets.Delete(c.VPC, vpcID, subnetID).Err
 }
 
-type ElasticIPOpts struct {
-	IPType        string
-	BandwidthSize int
-	BandwidthType string
-}
-
-func (c *Client) GetEIPStatus(eipID string) (string, error) {
-	eip, err := eips.Get(c.VPC, eipID).Extract()
-	if err != nil {
-		return "", err
-	}
-	return eip.Status, err
-}
-
-func (c *Client) CreateEIP(opts *ElasticIPOpts) (*eips.PublicIp, error) {
-	if opts.IPType == "" {
-		opts.IPType = "5_bgp"
-	}
-	if opts.BandwidthSize == 0 {
-		opts.BandwidthSize = 100
-	}
-	if opts.BandwidthType == "" {
-		opts.BandwidthType = "PER"
-	}
-
-	applyOpts := &eips.ApplyOpts{
-		IP: eips.PublicIpOpts{
-			Type: opts.IPType,
-		},
-		Bandwidth: eips.BandwidthOpts{
-			Name:      bandwidthName,
-			Size:      opts.BandwidthSize,
-			ShareType: opts.BandwidthType,
-		},
-	}
-	eip, err := eips.Apply(c.VPC, applyOpts).Extract()
-	if err != nil {
-		return nil, err
-	}
-	return eip, nil
-}
-
-func (c *Client) WaitForEIPActive(eipID string) error {
-	return golangsdk.WaitFor(30, func() (bool, error) {
-		status, err := c.GetEIPStatus(eipID)
-		if err != nil {
-			return true, err
+// waitForSubnetDeleted waits until subnet is gone
+func (c *Client) waitForSubnetDeleted(subnetID string) error {
+	return utils.WaitForSpecificOrError(func() (bool, error) {
+		_, err := c.GetSubnetStatus(subnetID)
+		if err == nil {
+			return false, nil
 		}
-		if status == "ACTIVE" || status == "DOWN" {
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
 			return true, nil
 		}
-		return false, nil
-	})
+		return true, err
+	}, maxAttempts, waitInterval)
 }
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/eips"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/subnets"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/dnatrules"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/natgateways"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/snatrules"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/peerings"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+)
+
+// CascadeOpts contains options of cascading VPC deletion
+type CascadeOpts struct {
+	DryRun                    bool // only discover resources, nothing is deleted
+	KeepEIPs                  bool // unbind EIPs from VPC ports instead of releasing them
+	DeleteMultiHomedInstances bool // delete instances having NICs in other VPCs, deletion fails if they are found otherwise
+}
+
+// VPCResources contains all resources found in VPC
+type VPCResources struct {
+	VPCID         string
+	Routes        []Route
+	Peerings      []peerings.Peering
+	NATGateways   []natgateways.NatGateway
+	SNATRules     []snatrules.SnatRule
+	DNATRules     []dnatrules.DnatRule
+	EIPs          []eips.PublicIp // EIPs bound to VPC ports
+	LoadBalancers []loadbalancers.LoadBalancer
+	Instances     []string
+	MultiHomed    []string     // instances having NICs in other VPCs too
+	Ports         []ports.Port // ports not managed by instances, load balancers or the cloud itself
+	Subnets       []subnets.Subnet
+}
+
+// String returns resources in deletion order, one per line
+func (r *VPCResources) String() string {
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	for _, route := range r.Routes {
+		add("route %s via %s %s", route.Destination, route.Type, route.NextHop)
+	}
+	for _, peering := range r.Peerings {
+		add("peering %s (%s)", peering.ID, peering.Name)
+	}
+	for _, rule := range r.DNATRules {
+		add("DNAT rule %s (%s:%d)", rule.ID, rule.FloatingIpAddress, rule.ExternalServicePort)
+	}
+	for _, rule := range r.SNATRules {
+		add("SNAT rule %s (%s)", rule.ID, rule.FloatingIPAddress)
+	}
+	for _, gateway := range r.NATGateways {
+		add("NAT gateway %s (%s)", gateway.ID, gateway.Name)
+	}
+	for _, eip := range r.EIPs {
+		add("EIP %s (%s)", eip.ID, eip.PublicAddress)
+	}
+	for _, lb := range r.LoadBalancers {
+		add("load balancer %s (%s)", lb.ID, lb.Name)
+	}
+	for _, instanceID := range r.Instances {
+		add("instance %s", instanceID)
+	}
+	for _, instanceID := range r.MultiHomed {
+		add("multi-homed instance %s", instanceID)
+	}
+	for _, port := range r.Ports {
+		add("port %s (%s)", port.ID, port.Name)
+	}
+	for _, subnet := range r.Subnets {
+		add("subnet %s (%s, %s)", subnet.ID, subnet.Name, subnet.CIDR)
+	}
+	add("VPC %s", r.VPCID)
+	return strings.Join(lines, "\n")
+}
+
+// splitVPCPorts returns IDs of instances owning the ports and ports which should be deleted explicitly
+// Ports of load balancers, NAT gateways, DHCP and routers are removed together with their owners
+func splitVPCPorts(portList []ports.Port) (instanceIDs []string, userPorts []ports.Port) {
+	seen := make(map[string]bool)
+	for _, port := range portList {
+		switch {
+		case strings.HasPrefix(port.DeviceOwner, "compute:"):
+			if port.DeviceID != "" && !seen[port.DeviceID] {
+				seen[port.DeviceID] = true
+				instanceIDs = append(instanceIDs, port.DeviceID)
+			}
+		case port.DeviceOwner == "", port.DeviceOwner == vipDeviceOwner:
+			userPorts = append(userPorts, port)
+		}
+	}
+	return
+}
+
+// multiHomed checks if some of instance ports are outside of VPC networks
+func multiHomed(instancePorts []ports.Port, vpcNetworks map[string]bool) bool {
+	for _, port := range instancePorts {
+		if !vpcNetworks[port.NetworkID] {
+			return true
+		}
+	}
+	return false
+}
+
+// DiscoverVPCResources returns all resources which prevent VPC from deletion
+// VPC, Neutron and NAT clients should be initialized
+func (c *Client) DiscoverVPCResources(vpcID string) (*VPCResources, error) {
+	vpc, err := c.GetVPCDetails(vpcID)
+	if err != nil {
+		return nil, err
+	}
+	result := &VPCResources{VPCID: vpcID}
+	if result.Routes, err = c.vpcRoutes(vpc); err != nil {
+		return nil, fmt.Errorf("error listing VPC routes: %s", err)
+	}
+
+	peeringList, err := peerings.List(c.NetworkV2, peerings.ListOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing VPC peerings: %s", err)
+	}
+	for _, peering := range peeringList {
+		if peering.RequestVpcInfo.VpcId == vpcID || peering.AcceptVpcInfo.VpcId == vpcID {
+			result.Peerings = append(result.Peerings, peering)
+		}
+	}
+
+	if result.NATGateways, err = c.ListNATGateways(vpcID); err != nil {
+		return nil, fmt.Errorf("error listing NAT gateways: %s", err)
+	}
+	for _, gateway := range result.NATGateways {
+		snat, err := c.ListSNATRules(gateway.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.SNATRules = append(result.SNATRules, snat...)
+		dnat, err := c.ListDNATRules(gateway.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.DNATRules = append(result.DNATRules, dnat...)
+	}
+
+	subnetList, err := subnets.List(c.VPC, subnets.ListOpts{VpcID: vpcID})
+	if err != nil {
+		return nil, fmt.Errorf("error listing subnets: %s", err)
+	}
+	var portList []ports.Port
+	vpcNetworks := make(map[string]bool)
+	for _, subnet := range subnetList {
+		if subnet.VpcID != vpcID {
+			continue
+		}
+		vpcNetworks[subnet.ID] = true
+		result.Subnets = append(result.Subnets, subnet)
+		page, err := ports.List(c.NetworkV2, ports.ListOpts{NetworkID: subnet.ID}).AllPages()
+		if err != nil {
+			return nil, fmt.Errorf("error listing ports: %s", err)
+		}
+		subnetPorts, err := ports.ExtractPorts(page)
+		if err != nil {
+			return nil, err
+		}
+		portList = append(portList, subnetPorts...)
+
+		page, err = loadbalancers.List(c.NetworkV2, loadbalancers.ListOpts{VipSubnetID: subnet.SubnetID}).AllPages()
+		if err != nil {
+			return nil, fmt.Errorf("error listing load balancers: %s", err)
+		}
+		lbList, err := loadbalancers.ExtractLoadBalancers(page)
+		if err != nil {
+			return nil, err
+		}
+		result.LoadBalancers = append(result.LoadBalancers, lbList...)
+	}
+	instanceIDs, userPorts := splitVPCPorts(portList)
+	result.Ports = userPorts
+	for _, instanceID := range instanceIDs {
+		page, err := ports.List(c.NetworkV2, ports.ListOpts{DeviceID: instanceID}).AllPages()
+		if err != nil {
+			return nil, fmt.Errorf("error listing ports of instance `%s`: %s", instanceID, err)
+		}
+		instancePorts, err := ports.ExtractPorts(page)
+		if err != nil {
+			return nil, err
+		}
+		if multiHomed(instancePorts, vpcNetworks) {
+			result.MultiHomed = append(result.MultiHomed, instanceID)
+			continue
+		}
+		result.Instances = append(result.Instances, instanceID)
+	}
+
+	portIDs := make(map[string]bool, len(portList))
+	for _, port := range portList {
+		portIDs[port.ID] = true
+	}
+	eipList, err := c.ListEIPs(eips.ListOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing EIPs: %s", err)
+	}
+	for _, eip := range eipList {
+		if eip.PortID != "" && portIDs[eip.PortID] {
+			result.EIPs = append(result.EIPs, eip)
+		}
+	}
+	return result, nil
+}
+
+// deleteLoadBalancerCascade removes load balancer together with its pools, members, monitors and listeners
+func (c *Client) deleteLoadBalancerCascade(lb loadbalancers.LoadBalancer) error {
+	for _, poolRef := range lb.Pools {
+		pool, err := pools.Get(c.NetworkV2, poolRef.ID).Extract()
+		if err != nil {
+			return err
+		}
+		if pool.MonitorID != "" {
+			if err := c.DeleteLBMonitor(pool.MonitorID); err != nil {
+				return fmt.Errorf("error deleting LB monitor: %s", err)
+			}
+			if err := c.waitForLBActive(lb.ID); err != nil {
+				return err
+			}
+		}
+		for _, member := range pool.Members {
+			if err := c.DeleteLBMember(pool.ID, member.ID); err != nil {
+				return fmt.Errorf("error deleting LB member: %s", err)
+			}
+			if err := c.waitForLBActive(lb.ID); err != nil {
+				return err
+			}
+		}
+		if err := c.DeleteLBPool(pool.ID); err != nil {
+			return fmt.Errorf("error deleting LB pool: %s", err)
+		}
+		if err := c.waitForLBActive(lb.ID); err != nil {
+			return err
+		}
+	}
+	for _, listener := range lb.Listeners {
+		if err := c.DeleteLBListener(listener.ID); err != nil {
+			return fmt.Errorf("error deleting LB listener: %s", err)
+		}
+		if err := c.waitForLBActive(lb.ID); err != nil {
+			return err
+		}
+	}
+	return c.DeleteLoadBalancer(lb.ID)
+}
+
+// DeleteVPCCascade removes VPC with everything inside it and returns list of found resources, `opts` can be nil
+// Resources are removed in dependency order, each stage is finished before the next one starts
+// VPC, Neutron, NAT and Compute v2 clients should be initialized
+func (c *Client) DeleteVPCCascade(vpcID string, opts *CascadeOpts) (*VPCResources, error) {
+	if opts == nil {
+		opts = &CascadeOpts{}
+	}
+	resources, err := c.DiscoverVPCResources(vpcID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return resources, nil
+	}
+	if len(resources.MultiHomed) > 0 && !opts.DeleteMultiHomedInstances {
+		return resources, fmt.Errorf("VPC `%s` has instances with NICs in other VPCs: %s", vpcID, strings.Join(resources.MultiHomed, ", "))
+	}
+
+	vpc, err := c.GetVPCDetails(vpcID)
+	if err != nil {
+		return resources, err
+	}
+	stages := []struct {
+		name   string
+		delete func() error
+	}{
+		{"routes", func() error {
+			if len(vpc.Routes) == 0 {
+				return nil
+			}
+			return c.updateStaticRoutes(vpc, nil)
+		}},
+		{"peerings", func() error {
+			mErr := &multierror.Error{}
+			for _, peering := range resources.Peerings {
+				mErr = multierror.Append(mErr, c.DeletePeering(peering.ID))
+			}
+			return mErr.ErrorOrNil()
+		}},
+		{"NAT rules", func() error {
+			mErr := &multierror.Error{}
+			for _, rule := range resources.DNATRules {
+				mErr = multierror.Append(mErr, c.DeleteDNATRule(rule.ID))
+			}
+			for _, rule := range resources.SNATRules {
+				mErr = multierror.Append(mErr, c.DeleteSNATRule(rule.ID))
+			}
+			return mErr.ErrorOrNil()
+		}},
+		{"NAT gateways", func() error {
+			mErr := &multierror.Error{}
+			for _, gateway := range resources.NATGateways {
+				mErr = multierror.Append(mErr, c.DeleteNATGateway(gateway.ID))
+			}
+			return mErr.ErrorOrNil()
+		}},
+		{"EIPs", func() error {
+			mErr := &multierror.Error{}
+			for _, eip := range resources.EIPs {
+				if opts.KeepEIPs {
+					mErr = multierror.Append(mErr, c.UnbindEIP(eip.ID))
+					continue
+				}
+				mErr = multierror.Append(mErr, c.ReleaseEIP(eip.ID))
+			}
+			return mErr.ErrorOrNil()
+		}},
+		{"load balancers", func() error {
+			mErr := &multierror.Error{}
+			for _, lb := range resources.LoadBalancers {
+				mErr = multierror.Append(mErr, c.deleteLoadBalancerCascade(lb))
+			}
+			return mErr.ErrorOrNil()
+		}},
+		{"instances", func() error {
+			instanceIDs := append(append([]string{}, resources.Instances...), resources.MultiHomed...)
+			if len(instanceIDs) == 0 {
+				return nil
+			}
+			return c.DeleteInstances(instanceIDs)
+		}},
+		{"ports", func() error {
+			mErr := &multierror.Error{}
+			for _, port := range resources.Ports {
+				mErr = multierror.Append(mErr, c.DeletePort(port.ID))
+			}
+			return mErr.ErrorOrNil()
+		}},
+		{"subnets", func() error {
+			mErr := &multierror.Error{}
+			for _, subnet := range resources.Subnets {
+				if err := c.DeleteSubnet(vpcID, subnet.ID); err != nil {
+					mErr = multierror.Append(mErr, err)
+					continue
+				}
+				mErr = multierror.Append(mErr, c.waitForSubnetDeleted(subnet.ID))
+			}
+			return mErr.ErrorOrNil()
+		}},
+		{"VPC", func() error {
+			if err := c.DeleteVPC(vpcID); err != nil {
+				return err
+			}
+			return c.waitForVPCDeleted(vpcID)
+		}},
+	}
+	for _, stage := range stages {
+		if err := stage.delete(); err != nil {
+			return resources, fmt.Errorf("error deleting %s of VPC `%s`: %s", stage.name, vpcID, err)
+		}
+	}
+	return resources, nil
+}
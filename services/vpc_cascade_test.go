@@ -0,0 +1,88 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/subnets"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitVPCPorts(t *testing.T) {
+	portList := []ports.Port{
+		{ID: "instance-port-1", DeviceOwner: "compute:eu-de-01", DeviceID: "instance-1"},
+		{ID: "instance-port-2", DeviceOwner: "compute:eu-de-01", DeviceID: "instance-1"},
+		{ID: "instance-port-3", DeviceOwner: "compute:eu-de-02", DeviceID: "instance-2"},
+		{ID: "vip-port", DeviceOwner: vipDeviceOwner},
+		{ID: "user-port"},
+		{ID: "dhcp-port", DeviceOwner: "network:dhcp"},
+		{ID: "lb-port", DeviceOwner: "neutron:LOADBALANCERV2"},
+	}
+	instanceIDs, userPorts := splitVPCPorts(portList)
+	assert.Equal(t, []string{"instance-1", "instance-2"}, instanceIDs)
+	require.Len(t, userPorts, 2)
+	assert.Equal(t, "vip-port", userPorts[0].ID)
+	assert.Equal(t, "user-port", userPorts[1].ID)
+}
+
+func TestMultiHomed(t *testing.T) {
+	vpcNetworks := map[string]bool{"subnet-1": true, "subnet-2": true}
+	assert.False(t, multiHomed([]ports.Port{{NetworkID: "subnet-1"}, {NetworkID: "subnet-2"}}, vpcNetworks))
+	assert.True(t, multiHomed([]ports.Port{{NetworkID: "subnet-1"}, {NetworkID: "other-subnet"}}, vpcNetworks))
+}
+
+func TestVPCResources_String(t *testing.T) {
+	resources := &VPCResources{
+		VPCID:      "vpc-id",
+		Routes:     []Route{{Type: RouteTypeVIP, Destination: "0.0.0.0/0", NextHop: "192.168.0.20"}},
+		Instances:  []string{"instance-id"},
+		MultiHomed: []string{"multi-homed-id"},
+		Subnets:    []subnets.Subnet{{ID: "subnet-id", Name: "subnet", CIDR: "192.168.0.0/24"}},
+	}
+	lines := strings.Split(resources.String(), "\n")
+	assert.Equal(t, []string{
+		"route 0.0.0.0/0 via vip 192.168.0.20",
+		"instance instance-id",
+		"multi-homed instance multi-homed-id",
+		"subnet subnet-id (subnet, 192.168.0.0/24)",
+		"VPC vpc-id",
+	}, lines)
+}
+
+func TestClient_DeleteVPCCascade(t *testing.T) {
+	client := computeClient(t)
+	require.NoError(t, client.InitNetworkV2())
+	require.NoError(t, client.InitNAT())
+
+	instance, _ := createTestInstance(t, client)
+	vpcID, err := client.FindVPC(vpcName)
+	require.NoError(t, err)
+
+	eip, err := client.CreateEIP(eipOptions)
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForEIPActive(eip.ID))
+	subnetID, err := client.FindSubnet(vpcID, subnetName)
+	require.NoError(t, err)
+	port, err := client.FindInstancePort(instance.ID, subnetID)
+	require.NoError(t, err)
+	require.NoError(t, client.BindEIP(eip.ID, port.ID))
+
+	resources, err := client.DeleteVPCCascade(vpcID, &CascadeOpts{DryRun: true})
+	require.NoError(t, err)
+	t.Logf("VPC resources:\n%s", resources)
+	assert.Equal(t, []string{instance.ID}, resources.Instances)
+	assert.Empty(t, resources.MultiHomed)
+	require.Len(t, resources.EIPs, 1)
+	assert.Equal(t, eip.ID, resources.EIPs[0].ID)
+	assert.Len(t, resources.Subnets, 1)
+
+	_, err = client.DeleteVPCCascade(vpcID, nil)
+	require.NoError(t, err)
+	_ = client.DeleteKeyPair(kpName)
+
+	vpcID, err = client.FindVPC(vpcName)
+	assert.NoError(t, err)
+	assert.Empty(t, vpcID)
+}
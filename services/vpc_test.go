@@ -53,3 +53,77 @@ func TestClient_CreateSubnet(t *testing.T) {
 
 	assert.NoError(t, client.DeleteVPC(vpc.ID))
 }
+
+func TestClient_CreateSubnetAutoCIDR(t *testing.T) {
+	cleanupResources(t)
+	client := authClient(t)
+	initNetwork(t, client)
+
+	vpc, err := client.CreateVPCWithOpts(vpcName, &VPCOpts{CIDR: "10.10.0.0/16"})
+	require.NoError(t, err)
+	defer deleteVPC(t, vpc.ID)
+	assert.Equal(t, "10.10.0.0/16", vpc.CIDR)
+
+	first, err := client.CreateSubnetWithOpts(vpc.ID, subnetName, nil)
+	require.NoError(t, err)
+	defer deleteSubnet(t, vpc.ID, first.ID)
+	require.NoError(t, client.WaitForSubnetStatus(first.ID, "ACTIVE"))
+	assert.Equal(t, "10.10.0.0/24", first.CIDR)
+	assert.Equal(t, "10.10.0.1", first.GatewayIP)
+
+	second, err := client.CreateSubnetWithOpts(vpc.ID, subnetName+"-2", &SubnetOpts{PrefixLength: 26})
+	require.NoError(t, err)
+	defer deleteSubnet(t, vpc.ID, second.ID)
+	require.NoError(t, client.WaitForSubnetStatus(second.ID, "ACTIVE"))
+	assert.Equal(t, "10.10.1.0/26", second.CIDR)
+}
+
+func TestValidateSubnetCIDR(t *testing.T) {
+	assert.NoError(t, validateSubnetCIDR("10.10.0.0/24", &SubnetOpts{}))
+	assert.NoError(t, validateSubnetCIDR("10.10.0.0/24", &SubnetOpts{CIDR: "10.10.0.1/24"}))
+	assert.NoError(t, validateSubnetCIDR("10.10.0.0/24", &SubnetOpts{PrefixLength: 24}))
+	assert.Error(t, validateSubnetCIDR("10.10.0.0/24", &SubnetOpts{CIDR: "10.10.1.0/24"}))
+	assert.Error(t, validateSubnetCIDR("10.10.0.0/24", &SubnetOpts{CIDR: "10.10.0.0/25"}))
+	assert.Error(t, validateSubnetCIDR("10.10.0.0/24", &SubnetOpts{PrefixLength: 26}))
+	assert.Error(t, validateSubnetCIDR("10.10.0.0/24", &SubnetOpts{CIDR: "invalid"}))
+}
+
+func TestClient_EnsureVPC(t *testing.T) {
+	cleanupResources(t)
+	client := authClient(t)
+	initNetwork(t, client)
+
+	vpc, created, err := client.EnsureVPC(vpcName, &VPCOpts{CIDR: "10.10.0.0/16"})
+	require.NoError(t, err)
+	defer deleteVPC(t, vpc.ID)
+	assert.True(t, created)
+	assert.Equal(t, "OK", vpc.Status)
+
+	reused, created, err := client.EnsureVPC(vpcName, &VPCOpts{CIDR: "10.10.0.0/16"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, vpc.ID, reused.ID)
+
+	reused, created, err = client.EnsureVPC(vpcName, nil)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, vpc.ID, reused.ID)
+
+	_, _, err = client.EnsureVPC(vpcName, &VPCOpts{CIDR: "10.20.0.0/16"})
+	assert.Error(t, err)
+
+	subnet, created, err := client.EnsureSubnet(vpc.ID, subnetName, &SubnetOpts{PrefixLength: 26})
+	require.NoError(t, err)
+	defer deleteSubnet(t, vpc.ID, subnet.ID)
+	assert.True(t, created)
+	assert.Equal(t, "ACTIVE", subnet.Status)
+	assert.Equal(t, "10.10.0.0/26", subnet.CIDR)
+
+	reusedSubnet, created, err := client.EnsureSubnet(vpc.ID, subnetName, nil)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, subnet.ID, reusedSubnet.ID)
+
+	_, _, err = client.EnsureSubnet(vpc.ID, subnetName, &SubnetOpts{CIDR: "10.10.1.0/24"})
+	assert.Error(t, err)
+}
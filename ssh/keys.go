@@ -0,0 +1,82 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	ErrNoAgent         = errors.New("SSH_AUTH_SOCK is not set")
+	ErrNoAgentIdentity = errors.New("no matching identity found in ssh-agent")
+)
+
+// PublicKeySource provides public key in authorized_keys format
+type PublicKeySource interface {
+	AuthorizedKey() ([]byte, error)
+}
+
+// AuthorizedKey returns public key of the generated key pair
+func (kp *KeyPair) AuthorizedKey() ([]byte, error) {
+	return kp.PublicKey, nil
+}
+
+// FileSource is a path to either private key or public key file
+type FileSource string
+
+// AuthorizedKey reads public key from file, deriving it from private key if required
+func (f FileSource) AuthorizedKey() ([]byte, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return nil, err
+	}
+	if pub, _, _, _, err := gossh.ParseAuthorizedKey(data); err == nil {
+		return gossh.MarshalAuthorizedKey(pub), nil
+	}
+	signer, err := gossh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("file %s contains neither public nor unencrypted private key: %s", f, err)
+	}
+	return gossh.MarshalAuthorizedKey(signer.PublicKey()), nil
+}
+
+// AgentSource is a comment of ssh-agent identity, the first identity is used if empty
+type AgentSource string
+
+// AuthorizedKey gets public key of ssh-agent identity
+func (a AgentSource) AuthorizedKey() ([]byte, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, ErrNoAgent
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh-agent: %s", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	keys, err := agent.NewClient(conn).List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ssh-agent identities: %s", err)
+	}
+	for _, key := range keys {
+		if a == "" || key.Comment == string(a) {
+			return gossh.MarshalAuthorizedKey(key), nil
+		}
+	}
+	return nil, ErrNoAgentIdentity
+}
+
+// PublicKeyFingerprint returns SHA256 fingerprint of public key in authorized_keys format
+func PublicKeyFingerprint(authorizedKey []byte) (string, error) {
+	pub, _, _, _, err := gossh.ParseAuthorizedKey(bytes.TrimSpace(authorizedKey))
+	if err != nil {
+		return "", ErrPublicKey
+	}
+	return gossh.FingerprintSHA256(pub), nil
+}